@@ -0,0 +1,137 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/asimihsan/version-enforcer/identifier"
+	"strings"
+)
+
+// incompatibility describes versions of two programs that are known not to work together.
+type incompatibility struct {
+	program          identifier.Program
+	requirement      string
+	other            identifier.Program
+	otherRequirement string
+	reason           string
+}
+
+// incompatibilities are the known incompatible versions of programs that are used together.
+var incompatibilities = []incompatibility{
+	{
+		program:          identifier.ProtocGenGo,
+		requirement:      "< 1.20.0",
+		other:            identifier.Protobuf,
+		otherRequirement: ">= 3.12.0",
+		reason:           "protoc 3.12 and later require protoc-gen-go 1.20 or later for proto3 optional fields; upgrade protoc-gen-go to 1.20 or later",
+	},
+	{
+		program:          identifier.ProtocGenGo,
+		requirement:      "< 1.34.0",
+		other:            identifier.Protobuf,
+		otherRequirement: ">= 27.0",
+		reason:           "protoc 27 and later require protoc-gen-go 1.34 or later for protobuf editions; upgrade protoc-gen-go to 1.34 or later",
+	},
+}
+
+// incompatiblePair is a pair of results whose installed versions are known to be incompatible.
+type incompatiblePair struct {
+	a, b    int
+	warning string
+}
+
+// incompatiblePairs returns the indexes in results of each pair of installed versions that are
+// known to be incompatible, with a warning about them.
+func incompatiblePairs(results []Result) []incompatiblePair {
+	installed := make(map[identifier.Program]int)
+	for i, result := range results {
+		if result.Installed == "" || result.program == nil {
+			continue
+		}
+		if _, ok := installed[*result.program]; !ok {
+			installed[*result.program] = i
+		}
+	}
+
+	var pairs []incompatiblePair
+	for _, inc := range incompatibilities {
+		i, ok := installed[inc.program]
+		if !ok {
+			continue
+		}
+		j, ok := installed[inc.other]
+		if !ok {
+			continue
+		}
+		a, b := results[i], results[j]
+		if identifier.Satisfies(a.Installed, inc.requirement) && identifier.Satisfies(b.Installed, inc.otherRequirement) {
+			warning := fmt.Sprintf("%s version %s is incompatible with %s version %s: %s", a.Name, a.Installed, b.Name, b.Installed, inc.reason)
+			pairs = append(pairs, incompatiblePair{a: i, b: j, warning: warning})
+		}
+	}
+	return pairs
+}
+
+// checkAssertEqual fails every passing result for a binary named in names, the config's
+// assert_equal, if the binaries do not all have the same installed version. Binaries whose version
+// could not be identified are not compared.
+func checkAssertEqual(results []Result, names []string) {
+	asserted := make(map[string]bool)
+	for _, name := range names {
+		asserted[name] = true
+	}
+
+	var compared []*Result
+	versions := make(map[string]bool)
+	for i := range results {
+		result := &results[i]
+		if asserted[result.Name] && result.Installed != "" {
+			compared = append(compared, result)
+			versions[result.Installed] = true
+		}
+	}
+	if len(versions) < 2 {
+		return
+	}
+
+	found := make([]string, 0, len(compared))
+	for _, result := range compared {
+		found = append(found, result.Name+" "+result.Installed)
+	}
+	message := fmt.Sprintf("assert_equal requires the same version, but found %s", strings.Join(found, ", "))
+	for _, result := range compared {
+		if result.Status != StatusPass {
+			// The result already failed for another reason, which is more useful to report.
+			continue
+		}
+		result.Satisfied = false
+		result.Status = StatusFail
+		result.Error = message
+	}
+}
+
+// warnIncompatible adds a warning to both results of each pair of installed versions in results
+// that are known to be incompatible, so that it is reported with them and --warnings-as-errors
+// fails them.
+func warnIncompatible(results []Result) {
+	for _, pair := range incompatiblePairs(results) {
+		results[pair.a].Warnings = append(results[pair.a].Warnings, pair.warning)
+		results[pair.b].Warnings = append(results[pair.b].Warnings, pair.warning)
+	}
+}
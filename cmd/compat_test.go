@@ -0,0 +1,157 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"context"
+	"github.com/asimihsan/version-enforcer/config"
+	"github.com/asimihsan/version-enforcer/identifier"
+	"github.com/rs/zerolog"
+	"strings"
+	"testing"
+)
+
+func TestIncompatiblePairs(t *testing.T) {
+	protoc, protocGenGo := identifier.Protobuf, identifier.ProtocGenGo
+	results := []Result{
+		{Name: "protoc", Installed: "3.19.1", Status: StatusPass, program: &protoc},
+		{Name: "protoc-gen-go", Installed: "1.3.5", Status: StatusPass, program: &protocGenGo},
+	}
+	pairs := incompatiblePairs(results)
+	if len(pairs) != 1 || pairs[0].a != 1 || pairs[0].b != 0 {
+		t.Fatalf("incompatiblePairs() = %+v, want protoc-gen-go and protoc", pairs)
+	}
+	if want := "protoc-gen-go version 1.3.5 is incompatible with protoc version 3.19.1"; !strings.Contains(pairs[0].warning, want) {
+		t.Errorf("warning = %q, want it to contain %q", pairs[0].warning, want)
+	}
+	if want := "protoc 3.12 and later require protoc-gen-go 1.20 or later"; !strings.Contains(pairs[0].warning, want) {
+		t.Errorf("warning = %q, want it to say %q", pairs[0].warning, want)
+	}
+
+	results[1].Installed = "1.28.1"
+	if pairs := incompatiblePairs(results); len(pairs) != 0 {
+		t.Errorf("incompatiblePairs() = %+v, want none", pairs)
+	}
+
+	results[0].Installed = ""
+	results[1].Installed = "1.3.5"
+	if pairs := incompatiblePairs(results); len(pairs) != 0 {
+		t.Errorf("incompatiblePairs() without an installed protoc = %+v, want none", pairs)
+	}
+
+	// Results are matched by the program they were identified as, not by their name.
+	results[0].Installed = "3.19.1"
+	results[0].program = nil
+	if pairs := incompatiblePairs(results); len(pairs) != 0 {
+		t.Errorf("incompatiblePairs() with protoc not identified as a program = %+v, want none", pairs)
+	}
+}
+
+func TestEnforceBinariesIncompatibleAliases(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	defer func(original func(context.Context, identifier.Program, identifier.IdentifyOptions, *zerolog.Logger) (identifier.Identification, error)) {
+		identifyWithOptions = original
+	}(identifyWithOptions)
+	identifyWithOptions = func(ctx context.Context, p identifier.Program, opts identifier.IdentifyOptions, zlog *zerolog.Logger) (identifier.Identification, error) {
+		if p == identifier.Protobuf {
+			return identifier.Identification{Version: "3.19.1"}, nil
+		}
+		return identifier.Identification{Version: "1.3.5"}, nil
+	}
+
+	cfg := &config.Config{Binary: []*config.Binary{
+		{Name: "protoc3", Program: "protoc", Version: "~3.19"},
+		{Name: "protoc-gen-go", Version: "~1.3"},
+	}}
+	results := enforceBinaries(context.Background(), cfg, &zlog)
+	warnIncompatible(results)
+	for _, result := range results {
+		if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "is incompatible with protoc3 version 3.19.1") {
+			t.Errorf("%s warnings = %q, want the incompatibility", result.Name, result.Warnings)
+		}
+	}
+}
+
+func TestWarnIncompatible(t *testing.T) {
+	golang, protoc, protocGenGo := identifier.Go, identifier.Protobuf, identifier.ProtocGenGo
+	results := []Result{
+		{Name: "go", Installed: "1.21.3", Satisfied: true, Status: StatusPass, program: &golang},
+		{Name: "protoc", Installed: "3.19.1", Satisfied: true, Status: StatusPass, program: &protoc},
+		{Name: "protoc-gen-go", Installed: "1.3.5", Satisfied: true, Status: StatusPass, program: &protocGenGo},
+	}
+	warnIncompatible(results)
+	if len(results[0].Warnings) != 0 {
+		t.Errorf("go warnings = %q, want none", results[0].Warnings)
+	}
+	for _, result := range results[1:] {
+		if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "is incompatible with") {
+			t.Errorf("%s warnings = %q, want the incompatibility", result.Name, result.Warnings)
+		}
+	}
+	if code := exitCodeForResults(results); code != ExitSuccess {
+		t.Errorf("exitCodeForResults() = %d, want %d", code, ExitSuccess)
+	}
+
+	// --warnings-as-errors fails both results, and --quiet still writes them.
+	promoteWarnings(results)
+	if code := exitCodeForResults(results); code != ExitVersionMismatch {
+		t.Errorf("exitCodeForResults() with warnings as errors = %d, want %d", code, ExitVersionMismatch)
+	}
+	if written := failuresAndWarnings(results); len(written) != 2 || written[0].Name != "protoc" {
+		t.Errorf("failuresAndWarnings() = %+v, want protoc and protoc-gen-go", written)
+	}
+}
+
+func TestCheckAssertEqual(t *testing.T) {
+	names := []string{"cmake", "ctest", "cpack"}
+	newResults := func(ctest string) []Result {
+		return []Result{
+			{Name: "cmake", Installed: "3.27.1", Satisfied: true, Status: StatusPass},
+			{Name: "ctest", Installed: ctest, Satisfied: true, Status: StatusPass},
+			{Name: "cpack", Installed: "3.27.1", Satisfied: true, Status: StatusPass},
+			{Name: "go", Installed: "1.21.3", Satisfied: true, Status: StatusPass},
+		}
+	}
+
+	results := newResults("3.27.1")
+	checkAssertEqual(results, names)
+	if code := exitCodeForResults(results); code != ExitSuccess {
+		t.Errorf("matching trio exit code = %d, want %d: %+v", code, ExitSuccess, results)
+	}
+
+	results = newResults("3.26.0")
+	checkAssertEqual(results, names)
+	want := "assert_equal requires the same version, but found cmake 3.27.1, ctest 3.26.0, cpack 3.27.1"
+	for _, result := range results[:3] {
+		if result.Status != StatusFail || result.Error != want {
+			t.Errorf("mismatched trio result = %+v, want failed with %q", result, want)
+		}
+	}
+	if results[3].Status != StatusPass {
+		t.Errorf("result not named by assert_equal = %+v, want pass", results[3])
+	}
+
+	// A binary that is not installed is not compared.
+	results = newResults("")
+	results[1].Status, results[1].Satisfied = StatusMissing, false
+	checkAssertEqual(results, names)
+	if results[0].Status != StatusPass || results[2].Status != StatusPass {
+		t.Errorf("results = %+v, want cmake and cpack to pass", results)
+	}
+}
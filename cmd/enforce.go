@@ -18,88 +18,693 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"github.com/asimihsan/version-enforcer/command"
 	"github.com/asimihsan/version-enforcer/config"
 	"github.com/asimihsan/version-enforcer/identifier"
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
+	"io"
 	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// retryBackoff is how long to wait before retrying a version command that failed to start. It
+// doubles for each retry after the first.
+const retryBackoff = 100 * time.Millisecond
+
+// Exit codes, so that CI pipelines can distinguish between kinds of failure.
+const (
+	ExitSuccess         = 0
+	ExitVersionMismatch = 1
+	ExitConfigError     = 2
+	ExitToolMissing     = 3
+	ExitInternalError   = 4
+)
+
+// identifyWithOptions, identifyGoModule, and identifyProbe identify installed binaries, and
+// findExecutables and lookPath find them without running them. fetchLatest fetches the latest
+// release of a binary. Tests replace them to avoid depending on what is installed or released.
+var (
+	identifyWithOptions = identifier.IdentifyWithOptions
+	identifyGoModule    = identifier.IdentifyGoModule
+	identifyProbe       = identifier.IdentifyProbe
+	findExecutables     = identifier.FindExecutables
+	lookPath            = exec.LookPath
+	fetchLatest         = identifier.FetchLatestVersion
 )
 
 var rootCmd = &cobra.Command{
-	Use:  "enforce --config <config file>",
-	Long: "Enforce tool versions",
+	Use: "enforce --config <config file>",
+	Long: `Enforce tool versions
+
+Exit codes:
+  0  all binaries satisfy their requirements
+  1  a binary's version does not satisfy its requirement
+  2  the config could not be loaded
+  3  a binary is not installed
+  4  an internal error, e.g. a binary's version could not be identified or the results could not be
+     written`,
 	Run: func(cmd *cobra.Command, args []string) {
-		zlog := zerolog.New(os.Stdout).With().Timestamp().Logger()
+		zlog := newLogger()
 
-		if verbose {
-			zerolog.SetGlobalLevel(zerolog.DebugLevel)
-		} else {
-			zerolog.SetGlobalLevel(zerolog.InfoLevel)
+		if err := validateFormat(format); err != nil {
+			zlog.Error().Err(err).Msg("invalid flags")
+			os.Exit(ExitConfigError)
+		}
+		if err := validateSummaryFormat(summaryFormat); err != nil {
+			zlog.Error().Err(err).Msg("invalid flags")
+			os.Exit(ExitConfigError)
+		}
+		if err := validateGroupBy(groupBy); err != nil {
+			zlog.Error().Err(err).Msg("invalid flags")
+			os.Exit(ExitConfigError)
+		}
+		if quiet && verbose {
+			zlog.Error().Msg("--quiet and --verbose cannot be used together")
+			os.Exit(ExitConfigError)
+		}
+		if minFoundDigits < 1 || minFoundDigits > 3 {
+			zlog.Error().Int("min-found-digits", minFoundDigits).Msg("--min-found-digits must be between 1 and 3")
+			os.Exit(ExitConfigError)
+		}
+		if retries < 0 {
+			zlog.Error().Int("retries", retries).Msg("--retries must not be negative")
+			os.Exit(ExitConfigError)
+		}
+		if retries > 0 {
+			identifier.SetRunner(identifier.Runner(command.Retry(command.RunCommandOutput, retries, retryBackoff)))
+		}
+
+		if watchConfig {
+			if cfgFile == "" || cfgFile == stdinConfig {
+				zlog.Error().Msg("--watch requires --config to be a file")
+				os.Exit(ExitConfigError)
+			}
+			os.Exit(watch(cmd.Context(), &zlog))
+		}
+		os.Exit(runEnforce(cmd.Context(), os.Stdout, os.Stderr, &zlog))
+	},
+}
+
+// runEnforce loads the config, enforces every binary's requirement, writes the results to stdout
+// and any summary to stderr, and returns the exit code.
+func runEnforce(ctx context.Context, stdout, stderr io.Writer, zlog *zerolog.Logger) int {
+	cfg, err := loadConfig(zlog)
+	if err != nil {
+		zlog.Error().Err(err).Msg("failed to load config")
+		return ExitConfigError
+	}
+	zlog.Debug().Interface("config", cfg).Msg("loaded config")
+
+	var lock config.Lock
+	if locked {
+		lock, err = config.LoadLock(lockPath)
+		if err != nil {
+			zlog.Error().Err(err).Str("path", lockPath).Msg("failed to load lockfile")
+			return ExitConfigError
+		}
+	}
+
+	results := enforceBinaries(ctx, cfg, zlog)
+	if locked {
+		checkLock(results, lock)
+	}
+	checkAssertEqual(results, cfg.AssertEqual)
+	warnIncompatible(results)
+	if warningsAsErrors {
+		promoteWarnings(results)
+	}
+	if outputFile != "" {
+		err = writeOutputFile(outputFile, stdout, stderr, results)
+	} else {
+		err = writeOutput(stdout, stderr, results)
+	}
+	if err != nil {
+		zlog.Error().Err(err).Msg("failed to write results")
+		return ExitInternalError
+	}
+	runOnFailure(ctx, stderr, results, zlog)
+
+	return exitCodeForResults(results)
+}
+
+// newLogger returns the logger used by all commands, and sets the global log level according to
+// --verbose and --quiet. It logs to stderr, so that stdout only has results and can be piped, e.g.
+// into jq with --format json.
+func newLogger() zerolog.Logger {
+	switch {
+	case verbose:
+		zerolog.SetGlobalLevel(zerolog.DebugLevel)
+	case quiet:
+		zerolog.SetGlobalLevel(zerolog.ErrorLevel)
+	default:
+		zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	}
+	return zerolog.New(os.Stderr).With().Timestamp().Logger()
+}
+
+// stdinConfig is the --config value that reads the config from stdin.
+const stdinConfig = "-"
+
+// stdin is where a --config of stdinConfig is read from. It is a variable so that tests can replace
+// it.
+var stdin io.Reader = os.Stdin
+
+// loadConfigFile loads the --config file in the --config-format, or reads it from stdin if the
+// --config is stdinConfig.
+func loadConfigFile(zlog *zerolog.Logger) (*config.Config, error) {
+	if cfgFile != stdinConfig {
+		return config.LoadConfigFormat(cfgFile, cfgFormat, zlog)
+	}
+	src, err := io.ReadAll(stdin)
+	if err != nil {
+		zlog.Error().Err(err).Msg("failed to read config from stdin")
+		return nil, err
+	}
+	return config.LoadConfigBytes(src, cfgFormat, zlog)
+}
+
+// loadConfig loads the --config file, adds binaries pinned in the --tool-versions file that the
+// config does not already configure, pins the exact versions in the --pins file, and finally checks
+// the result against the --baseline config. The --config file may be omitted if --tool-versions or
+// --pins is set.
+func loadConfig(zlog *zerolog.Logger) (*config.Config, error) {
+	cfg := &config.Config{}
+	if cfgFile != "" || (toolVersionsFile == "" && pinsFile == "") {
+		loaded, err := loadConfigFile(zlog)
+		if err != nil {
+			return nil, err
+		}
+		cfg = loaded
+	}
+
+	if toolVersionsFile != "" {
+		toolVersions, err := config.LoadToolVersions(toolVersionsFile, zlog)
+		if err != nil {
+			return nil, err
+		}
+		cfg.AddMissing(toolVersions)
+	}
+
+	if pinsFile != "" {
+		pins, err := config.LoadPins(pinsFile, zlog)
+		if err != nil {
+			return nil, err
+		}
+		if err := cfg.ApplyPins(pins); err != nil {
+			zlog.Error().Err(err).Str("path", pinsFile).Msg("failed to apply pinned versions")
+			return nil, err
 		}
+	}
 
-		cfg, err := config.LoadConfig(cfgFile, &zlog)
+	if baselineFile != "" {
+		baseline, err := config.LoadConfig(baselineFile, zlog)
+		if err != nil {
+			zlog.Error().Err(err).Str("path", baselineFile).Msg("failed to load baseline config")
+			return nil, err
+		}
+		cfg, err = config.MergeBaseline(baseline, cfg)
 		if err != nil {
-			zlog.Error().Err(err).Msg("failed to load config")
-			os.Exit(1)
+			zlog.Error().Err(err).Msg("failed to merge config with baseline")
+			return nil, err
 		}
-		zlog.Debug().Interface("config", cfg).Msg("loaded config")
+	}
+
+	return cfg, nil
+}
 
-		anyFailures := false
-		for _, binary := range cfg.Binary {
-			program, err := identifier.GetProgram(binary.Name)
-			if err != nil {
-				zlog.Error().Err(err).Interface("binary", binary).Msg("failed to get program")
-				os.Exit(1)
+// enforceBinaries identifies the version of every binary in the config and checks it against the
+// binary's requirement. Skipped binaries have no result.
+func enforceBinaries(ctx context.Context, cfg *config.Config, zlog *zerolog.Logger) []Result {
+	results := make([]Result, 0, len(cfg.Binary))
+	missing := make(notInstalled)
+	skipped := skippedNames()
+	for _, binary := range cfg.Binary {
+		if skipped[binary.Name] {
+			zlog.Debug().Str("name", binary.Name).Msg("skipping binary")
+			continue
+		}
+		if binary.Absent {
+			results = append(results, warnIfOptional(binary, enforceAbsent(binary, zlog)))
+			continue
+		}
+		if !binary.IsGlob() {
+			results = append(results, warnIfOptional(binary, enforceBinary(ctx, binary, missing, zlog)))
+			continue
+		}
+
+		matches, err := expandGlob(binary, zlog)
+		if err != nil {
+			results = append(results, warnIfOptional(binary, Result{
+				Name:        binary.Name,
+				Required:    binary.RequirementString(),
+				Status:      StatusMissing,
+				Error:       err.Error(),
+				InstallHint: installHint(binary),
+			}))
+			continue
+		}
+		for _, match := range matches {
+			if skipped[match.Name] {
+				zlog.Debug().Str("name", match.Name).Msg("skipping binary")
+				continue
 			}
+			results = append(results, warnIfOptional(match, enforceBinary(ctx, match, missing, zlog)))
+		}
+	}
+	return results
+}
+
+// warnIfOptional turns the failure of an optional binary into a passing result with a warning, so
+// that it is reported but does not fail the check unless --warnings-as-errors is set.
+func warnIfOptional(binary *config.Binary, result Result) Result {
+	if !binary.Optional || result.Status == StatusPass {
+		return result
+	}
+	result.Warnings = append(result.Warnings, result.message()+" (optional)")
+	result.Status = StatusPass
+	return result
+}
+
+// enforceAbsent checks that a binary that must be absent is not installed, by looking for it in
+// $PATH, or at its path if set, without running it. A glob fails if any executable matches it.
+func enforceAbsent(binary *config.Binary, zlog *zerolog.Logger) Result {
+	result := Result{
+		Name:     binary.Name,
+		Required: binary.RequirementString(),
+	}
+
+	var paths []string
+	switch {
+	case binary.IsGlob():
+		var err error
+		paths, err = findExecutables(binary.Name)
+		if err != nil {
+			result.Status = StatusError
+			result.Error = err.Error()
+			return result
+		}
+	default:
+		name := binary.Name
+		if binary.Path != "" {
+			name = binary.Path
+		}
+		if path, err := lookPath(name); err == nil || errors.Is(err, exec.ErrDot) {
+			paths = append(paths, path)
+		}
+	}
+
+	if len(paths) == 0 {
+		zlog.Debug().Str("name", binary.Name).Msg("binary is absent")
+		result.Satisfied = true
+		result.Status = StatusPass
+		return result
+	}
+	zlog.Debug().Str("name", binary.Name).Strs("paths", paths).Msg("binary must be absent but is installed")
+	result.Status = StatusFail
+	result.ResolvedPath = paths[0]
+	result.Error = fmt.Sprintf("must be absent, but is installed at %s", strings.Join(paths, ", "))
+	result.InstallHint = binary.InstallHint
+	return result
+}
+
+// skipEnv lists binaries to skip, separated by commas, in addition to those given with --skip.
+const skipEnv = "ENFORCE_SKIP"
+
+// skippedNames returns the names of the binaries to skip, from --skip and $ENFORCE_SKIP.
+func skippedNames() map[string]bool {
+	names := make(map[string]bool)
+	for _, name := range append(strings.Split(os.Getenv(skipEnv), ","), skip...) {
+		if name = strings.TrimSpace(name); name != "" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// expandGlob returns a copy of a binary whose name is a glob for each executable in $PATH that
+// matches it, named after and with the path of the executable. It returns an error if nothing
+// matches, unless the binary's on_no_match is "skip".
+func expandGlob(binary *config.Binary, zlog *zerolog.Logger) ([]*config.Binary, error) {
+	paths, err := findExecutables(binary.Name)
+	if err != nil {
+		return nil, err
+	}
+	zlog.Debug().Str("pattern", binary.Name).Strs("paths", paths).Msg("expanded glob")
+	if len(paths) == 0 && binary.OnNoMatch != config.OnNoMatchSkip {
+		return nil, fmt.Errorf("%w: no executable in $PATH matches %s", identifier.ErrProgramNotInstalled, binary.Name)
+	}
+
+	matches := make([]*config.Binary, 0, len(paths))
+	for _, path := range paths {
+		match := *binary
+		match.Name = filepath.Base(path)
+		match.Path = path
+		matches = append(matches, &match)
+	}
+	return matches, nil
+}
+
+// notInstalled maps executables that were found not to be installed during a run to the error
+// from looking them up, so that a tool configured by several blocks is only looked up once.
+type notInstalled map[string]error
+
+func enforceBinary(ctx context.Context, binary *config.Binary, missing notInstalled, zlog *zerolog.Logger) Result {
+	result := Result{
+		Name:     binary.Name,
+		Required: binary.RequirementString(),
+	}
+
+	result.InstallHint = installHint(binary)
+	result.program = binaryProgram(binary)
 
-			version, err := identifier.Identify(*program, &zlog)
-			if err != nil {
-				zlog.Error().Err(err).Msg("failed to identify program")
-				os.Exit(1)
+	executable := executableName(binary)
+	identification, err := identifier.Identification{}, missing[executable]
+	if err != nil {
+		zlog.Debug().Str("executable", executable).Msg("already found not to be installed")
+	} else {
+		identification, err = identifyBinary(ctx, binary, zlog)
+		if errors.Is(err, identifier.ErrProgramNotInstalled) {
+			missing[executable] = err
+		}
+	}
+	if err != nil {
+		zlog.Debug().Err(err).Interface("binary", binary).Msg("failed to identify program")
+		result.Status = StatusError
+		switch {
+		case errors.Is(err, identifier.ErrProgramNotInstalled):
+			result.Status = StatusMissing
+		case errors.Is(err, identifier.ErrOutsidePathPrefix):
+			result.Status = StatusFail
+		}
+		result.Error = err.Error()
+		return result
+	}
+	version := identification.Version
+	result.Installed = string(version)
+	result.Commit = identification.Commit
+	result.ResolvedPath = identification.Path
+	result.Note = identification.Note
+	result.Method = identification.Method
+	zlog.Debug().Str("name", binary.Name).Str("method", identification.Method).Msg("identified version")
+	if explain {
+		explanation, err := binary.Explain(string(version))
+		if err != nil {
+			zlog.Debug().Err(err).Interface("binary", binary).Msg("failed to explain requirement")
+		}
+		result.Explanation = explanation
+	}
+
+	// --min-found-digits and --strict-semver only apply to semver versions, not e.g. "2023c".
+	if usesSemver(binary) {
+		if err := identifier.CheckMinComponents(string(version), minFoundDigits); err != nil {
+			zlog.Debug().Err(err).Interface("binary", binary).Msg("version has too few components")
+			result.Status = StatusFail
+			result.Error = err.Error()
+			return result
+		}
+
+		if strictSemver {
+			if _, err := identifier.ParseStrictVersion(string(version)); err != nil {
+				zlog.Debug().Err(err).Interface("binary", binary).Msg("version is not strict semver")
+				result.Status = StatusFail
+				result.Error = err.Error()
+				return result
 			}
+		}
+	}
 
-			if !identifier.Satisfies(string(version), binary.Version) {
-				zlog.Debug().
-					Interface("version", version).
-					Interface("binary", binary).
-					Msg("version does not satisfy requirement")
-				msg := fmt.Sprintf("%s version %s does not satisfy requirement %s", binary.Name, version, binary.Version)
-				PrintErrorLine(msg)
-				anyFailures = true
+	satisfied, err := binary.Satisfies(string(version))
+	if err != nil || !satisfied {
+		zlog.Debug().
+			Err(err).
+			Interface("identification", identification).
+			Interface("binary", binary).
+			Msg("version does not satisfy requirement")
+		result.Status = StatusFail
+		if err != nil {
+			result.Error = err.Error()
+		} else {
+			result.Reason = failureReason(string(version), binary)
+			result.Gap = describeGap(string(version), binary)
+		}
+		return result
+	}
 
-				continue
-			} else {
-				zlog.Debug().
-					Interface("version", version).
-					Interface("binary", binary).
-					Msg("version satisfies requirement")
-				if verbose {
-					msg := fmt.Sprintf("%s version %s satisfies requirement %s", binary.Name, version, binary.Version)
-					PrintSuccessLine(msg)
-				}
+	if isExcluded(string(version), binary.Exclude) {
+		zlog.Debug().
+			Interface("version", version).
+			Interface("binary", binary).
+			Msg("version is excluded")
+		result.Status = StatusFail
+		result.Error = "version is excluded"
+		return result
+	}
+
+	if binary.Commit != "" && !commitMatches(identification.Commit, binary.Commit) {
+		zlog.Debug().
+			Interface("identification", identification).
+			Interface("binary", binary).
+			Msg("commit does not match")
+		result.Status = StatusFail
+		if identification.Commit == "" {
+			result.Error = fmt.Sprintf("commit %s is required, but the version output does not include a commit", binary.Commit)
+		} else {
+			result.Error = fmt.Sprintf("commit %s does not match required commit %s", identification.Commit, binary.Commit)
+		}
+		return result
+	}
+
+	if binary.LatestSource != "" {
+		if outdated := checkLatest(ctx, binary, string(version), zlog); outdated != "" {
+			if binary.OnOutdated == config.OnOutdatedError {
+				result.Status = StatusFail
+				result.Error = outdated
+				return result
 			}
+			result.Warnings = append(result.Warnings, fmt.Sprintf("%s version %s is %s", binary.Name, version, outdated))
 		}
+	}
 
-		if anyFailures {
-			os.Exit(1)
+	zlog.Debug().
+		Interface("version", version).
+		Interface("binary", binary).
+		Msg("version satisfies requirement")
+	result.Satisfied = true
+	result.Status = StatusPass
+	result.InstallHint = ""
+	return result
+}
+
+// failureReason returns whether a version that does not satisfy the binary's requirement is too old
+// or too new, or an empty string if it cannot be classified.
+func failureReason(version string, binary *config.Binary) string {
+	c, err := binary.CompareToRequirement(version)
+	switch {
+	case err != nil:
+		return ""
+	case c < 0:
+		return ReasonTooOld
+	case c > 0:
+		return ReasonTooNew
+	default:
+		return ""
+	}
+}
+
+// describeGap describes how far a version that does not satisfy the binary's requirement is from
+// the nearest version that does, or returns an empty string if the requirement is not a single
+// semver requirement.
+func describeGap(version string, binary *config.Binary) string {
+	if !usesSemver(binary) {
+		return ""
+	}
+	requirement, err := binary.Requirement()
+	if err != nil {
+		return ""
+	}
+	v, err := identifier.ParseVersion(version)
+	if err != nil {
+		return ""
+	}
+	return identifier.DescribeGap(*v, *requirement)
+}
+
+// usesSemver returns true if the binary's versions are compared as semver.
+func usesSemver(binary *config.Binary) bool {
+	comparator, err := binary.VersionComparator()
+	if err != nil {
+		return true
+	}
+	_, ok := comparator.(identifier.SemverComparator)
+	return ok
+}
+
+// isExcluded returns true if version is equal to any of the excluded versions.
+func isExcluded(version string, exclude []string) bool {
+	v, err := identifier.ParseVersion(version)
+	if err != nil {
+		return false
+	}
+	for _, excluded := range exclude {
+		e, err := identifier.ParseVersion(excluded)
+		if err != nil {
+			continue
 		}
-	},
+		if identifier.CompareSemverVersions(*v, *e) == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// commitMatches returns true if the installed and required commits are the same, allowing either
+// to be abbreviated, e.g. "3a31588" matches "3a31588ad33fe3b89af5a2a54ee1d25bfe6eaa5e".
+func commitMatches(installed string, required string) bool {
+	if installed == "" || required == "" {
+		return false
+	}
+	installed, required = strings.ToLower(installed), strings.ToLower(required)
+	return strings.HasPrefix(installed, required) || strings.HasPrefix(required, installed)
+}
+
+// identifyBinary returns the installed version of the binary, using its version environment
+// variable, probe, version source, path, path prefix, and working directory if set.
+func identifyBinary(ctx context.Context, binary *config.Binary, zlog *zerolog.Logger) (identifier.Identification, error) {
+	if binary.WorkingDir != "" {
+		ctx = command.WithDir(ctx, binary.WorkingDir)
+	}
+	if binary.VersionEnv != "" {
+		return identifier.IdentifyEnv(binary.VersionEnv, zlog)
+	}
+	if binary.Probe != "" {
+		return identifyProbe(ctx, binary.Probe, zlog)
+	}
+
+	opts := identifier.IdentifyOptions{
+		Path:       binary.Path,
+		Args:       binary.VersionArgs,
+		PathPrefix: binary.PathPrefix,
+		Invoker:    binary.Invoker,
+	}
+	if binary.VersionSource == config.VersionSourceGoVersionM {
+		return identifyGoModule(ctx, binary.Name, opts, zlog)
+	}
+
+	program, err := identifier.GetProgram(binary.ProgramName())
+	if err != nil {
+		zlog.Debug().Err(err).Interface("binary", binary).Msg("failed to get program")
+		return identifier.Identification{}, err
+	}
+	return identifyWithOptions(ctx, *program, opts, zlog)
+}
+
+// executableName returns the name that the binary's executable is looked up by, so that blocks
+// that name the same program by different aliases share it.
+func executableName(binary *config.Binary) string {
+	if binary.Probe != "" {
+		return binary.Probe
+	}
+	if binary.Path != "" {
+		return binary.Path
+	}
+	name := binary.Name
+	if binary.VersionSource != config.VersionSourceGoVersionM {
+		if program, err := identifier.GetProgram(binary.ProgramName()); err == nil {
+			name = identifier.GetProgramName(*program)
+		}
+	}
+	if len(binary.Invoker) > 0 {
+		return strings.Join(append(append([]string{}, binary.Invoker...), name), " ")
+	}
+	return name
+}
+
+// binaryProgram returns the supported program that the binary's version is identified as, or nil if
+// it is not one, e.g. with version_source = "go-version-m".
+func binaryProgram(binary *config.Binary) *identifier.Program {
+	if binary.VersionSource == config.VersionSourceGoVersionM {
+		return nil
+	}
+	program, err := identifier.GetProgram(binary.ProgramName())
+	if err != nil {
+		return nil
+	}
+	return program
+}
+
+// installHint returns the binary's install hint, falling back to the built-in hint for its program.
+func installHint(binary *config.Binary) string {
+	if binary.InstallHint != "" {
+		return binary.InstallHint
+	}
+	if program, err := identifier.GetProgram(binary.ProgramName()); err == nil {
+		return identifier.GetInstallHint(*program)
+	}
+	return ""
 }
 
 // PrintErrorLine prints an error message in bright red.
 func PrintErrorLine(message string) {
-	fmt.Printf("\033[31;1m%s\033[0m %s\n", "Error:", message)
+	fprintErrorLine(os.Stdout, message)
 }
 
 // PrintSuccessLine prints a success message in bright green.
 func PrintSuccessLine(message string) {
-	fmt.Printf("\033[32;1m%s\033[0m %s\n", "Success:", message)
+	fprintSuccessLine(os.Stdout, message)
+}
+
+func fprintErrorLine(w io.Writer, message string) {
+	fmt.Fprintf(w, "\033[31;1m%s\033[0m %s\n", "Error:", message)
+}
+
+func fprintSuccessLine(w io.Writer, message string) {
+	fmt.Fprintf(w, "\033[32;1m%s\033[0m %s\n", "Success:", message)
+}
+
+// fprintWarningLine prints a warning about a result that passed, with a yellow prefix.
+func fprintWarningLine(w io.Writer, message string) {
+	fmt.Fprintf(w, "\033[33;1m%s\033[0m %s\n", "Warning:", message)
+}
+
+// fprintExplanationLine prints a line of an explanation from --explain, with a cyan prefix.
+func fprintExplanationLine(w io.Writer, message string) {
+	fmt.Fprintf(w, "\033[36m%s\033[0m %s\n", "Explain:", message)
+}
+
+// fprintPathLine prints the path of the executable that was checked, and where it links to if it
+// is a symlink, e.g. into a Homebrew Cellar, so that users can tell which copy of a program was run.
+func fprintPathLine(w io.Writer, path string) {
+	if target, err := filepath.EvalSymlinks(path); err == nil && target != path {
+		path = path + " -> " + target
+	}
+	fmt.Fprintf(w, "\033[36m%s\033[0m %s\n", "Path:", path)
+}
+
+// fprintMethodLine prints how a version was found, with a cyan prefix.
+func fprintMethodLine(w io.Writer, method string) {
+	fmt.Fprintf(w, "\033[36m%s\033[0m %s\n", "Via:", method)
+}
+
+// fprintNoteLine prints an informational note about a result, with a cyan prefix.
+func fprintNoteLine(w io.Writer, message string) {
+	fmt.Fprintf(w, "\033[36m%s\033[0m %s\n", "Note:", message)
+}
+
+// fprintHintLine prints a hint, e.g. how to install a tool, with a bright yellow prefix.
+func fprintHintLine(w io.Writer, message string) {
+	fmt.Fprintf(w, "\033[33;1m%s\033[0m %s\n", "Hint:", message)
 }
 
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(ExitConfigError)
 	}
 }
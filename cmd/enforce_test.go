@@ -0,0 +1,582 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/asimihsan/version-enforcer/config"
+	"github.com/asimihsan/version-enforcer/identifier"
+	"github.com/rs/zerolog"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIsExcluded(t *testing.T) {
+	exclude := []string{"1.4.2", "1.4.5"}
+
+	tests := []struct {
+		version  string
+		expected bool
+	}{
+		{"1.4.2", true},
+		{"1.4.5", true},
+		{"1.4.3", false},
+		{"1.4", false},
+	}
+
+	for _, test := range tests {
+		// Every version matches the requirement, so only the exclusion decides.
+		if !identifier.Satisfies(test.version, "~1") {
+			t.Fatalf("Satisfies(%s, ~1) = false, want true", test.version)
+		}
+		actual := isExcluded(test.version, exclude)
+		if actual != test.expected {
+			t.Errorf("isExcluded(%s, %v) = %t, want %t", test.version, exclude, actual, test.expected)
+		}
+	}
+}
+
+func TestCommitMatches(t *testing.T) {
+	full := "3a31588ad33fe3b89af5a2a54ee1d25bfe6eaa5e"
+	tests := []struct {
+		installed string
+		required  string
+		expected  bool
+	}{
+		{full, full, true},
+		{full, "3a31588", true},
+		{"3a31588ad3", full, true},
+		{full, "3A31588", true},
+		{full, "3a31589", false},
+		{"", "3a31588", false},
+	}
+	for _, test := range tests {
+		actual := commitMatches(test.installed, test.required)
+		if actual != test.expected {
+			t.Errorf("commitMatches(%s, %s) = %t, want %t", test.installed, test.required, actual, test.expected)
+		}
+	}
+}
+
+func TestEnforceBinariesLooksUpMissingProgramOnce(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	lookups := 0
+	defer func(original func(context.Context, identifier.Program, identifier.IdentifyOptions, *zerolog.Logger) (identifier.Identification, error)) {
+		identifyWithOptions = original
+	}(identifyWithOptions)
+	identifyWithOptions = func(ctx context.Context, p identifier.Program, opts identifier.IdentifyOptions, zlog *zerolog.Logger) (identifier.Identification, error) {
+		lookups++
+		return identifier.Identification{}, fmt.Errorf("%w: go not found in $PATH", identifier.ErrProgramNotInstalled)
+	}
+
+	cfg := &config.Config{Binary: []*config.Binary{
+		{Name: "go", Version: "^1.21"},
+		{Name: "go", Version: ">=1.20"},
+	}}
+	results := enforceBinaries(context.Background(), cfg, &zlog)
+	if lookups != 1 {
+		t.Errorf("enforceBinaries looked up go %d times, want 1", lookups)
+	}
+	for i, result := range results {
+		if result.Status != StatusMissing {
+			t.Errorf("enforceBinaries result %d status = %s, want %s", i, result.Status, StatusMissing)
+		}
+	}
+}
+
+func TestEnforceBinariesFailureReason(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	defer func(original func(context.Context, identifier.Program, identifier.IdentifyOptions, *zerolog.Logger) (identifier.Identification, error)) {
+		identifyWithOptions = original
+	}(identifyWithOptions)
+	identifyWithOptions = func(ctx context.Context, p identifier.Program, opts identifier.IdentifyOptions, zlog *zerolog.Logger) (identifier.Identification, error) {
+		return identifier.Identification{Version: "1.22.0"}, nil
+	}
+
+	cfg := &config.Config{Binary: []*config.Binary{
+		{Name: "go", Version: ">= 1.23"},
+		{Name: "go", Version: "< 1.22"},
+		{Name: "go", Version: "~1.22"},
+	}}
+	results := enforceBinaries(context.Background(), cfg, &zlog)
+	expected := []string{ReasonTooOld, ReasonTooNew, ""}
+	for i, result := range results {
+		if result.Reason != expected[i] {
+			t.Errorf("result %d reason = %q, want %q", i, result.Reason, expected[i])
+		}
+	}
+	if want := "go version 1.22.0 is newer than allowed by < 1.22 (1 minor version ahead)"; results[1].message() != want {
+		t.Errorf("message() = %q, want %q", results[1].message(), want)
+	}
+}
+
+func TestEnforceBinariesInstallHint(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	defer func(original func(context.Context, identifier.Program, identifier.IdentifyOptions, *zerolog.Logger) (identifier.Identification, error)) {
+		identifyWithOptions = original
+	}(identifyWithOptions)
+	identifyWithOptions = func(ctx context.Context, p identifier.Program, opts identifier.IdentifyOptions, zlog *zerolog.Logger) (identifier.Identification, error) {
+		return identifier.Identification{Version: "1.20.5"}, nil
+	}
+
+	cfg := &config.Config{Binary: []*config.Binary{
+		{Name: "go", Version: "~1.21"},
+		{Name: "go", Version: "~1.21", InstallHint: "install with: asdf install golang 1.21.3"},
+		{Name: "go", Version: "~1.20"},
+	}}
+	results := enforceBinaries(context.Background(), cfg, &zlog)
+	builtIn := identifier.GetInstallHint(identifier.Go)
+	if builtIn == "" || results[0].InstallHint != builtIn {
+		t.Errorf("result 0 install hint = %q, want the built-in hint %q", results[0].InstallHint, builtIn)
+	}
+	if want := "install with: asdf install golang 1.21.3"; results[1].InstallHint != want {
+		t.Errorf("result 1 install hint = %q, want %q", results[1].InstallHint, want)
+	}
+	if results[2].Status != StatusPass || results[2].InstallHint != "" {
+		t.Errorf("result 2 = %+v, want a pass without an install hint", results[2])
+	}
+
+	var text bytes.Buffer
+	writeText(&text, results[1:2])
+	if want := "Hint:\033[0m install with: asdf install golang 1.21.3\n"; !strings.Contains(text.String(), want) {
+		t.Errorf("writeText() = %q, want it to contain %q", text.String(), want)
+	}
+	var out bytes.Buffer
+	if err := writeJSON(&out, results[:1]); err != nil {
+		t.Fatalf("writeJSON returned error: %v", err)
+	}
+	var decoded []Result
+	if err := json.Unmarshal(out.Bytes(), &decoded); err != nil || len(decoded) != 1 || decoded[0].InstallHint != builtIn {
+		t.Errorf("writeJSON() = %s, want install_hint %q", out.String(), builtIn)
+	}
+}
+
+func TestEnforceBinariesGlob(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	defer func(original func(string) ([]string, error)) { findExecutables = original }(findExecutables)
+	findExecutables = func(pattern string) ([]string, error) {
+		if pattern == "go1.*" {
+			return []string{"/home/asim/go/bin/go1.20.7", "/home/asim/go/bin/go1.21.3"}, nil
+		}
+		return nil, nil
+	}
+	var identified []string
+	defer func(original func(context.Context, identifier.Program, identifier.IdentifyOptions, *zerolog.Logger) (identifier.Identification, error)) {
+		identifyWithOptions = original
+	}(identifyWithOptions)
+	identifyWithOptions = func(ctx context.Context, p identifier.Program, opts identifier.IdentifyOptions, zlog *zerolog.Logger) (identifier.Identification, error) {
+		identified = append(identified, opts.Path)
+		return identifier.Identification{Version: identifier.Version(strings.TrimPrefix(filepath.Base(opts.Path), "go"))}, nil
+	}
+
+	cfg := &config.Config{Binary: []*config.Binary{
+		{Name: "go1.*", Program: "go", Version: ">= 1.21"},
+		{Name: "python3.*", Program: "go", Version: ">= 1.21"},
+		{Name: "ruby*", Program: "go", Version: ">= 1.21", OnNoMatch: config.OnNoMatchSkip},
+	}}
+	results := enforceBinaries(context.Background(), cfg, &zlog)
+	if len(results) != 3 {
+		t.Fatalf("enforceBinaries returned %d results, want 3: %+v", len(results), results)
+	}
+	if results[0].Name != "go1.20.7" || results[0].Status != StatusFail {
+		t.Errorf("result 0 = %+v, want go1.20.7 to fail", results[0])
+	}
+	if results[1].Name != "go1.21.3" || results[1].Status != StatusPass {
+		t.Errorf("result 1 = %+v, want go1.21.3 to pass", results[1])
+	}
+	if results[2].Name != "python3.*" || results[2].Status != StatusMissing {
+		t.Errorf("result 2 = %+v, want python3.* to be missing", results[2])
+	}
+	if strings.Join(identified, " ") != "/home/asim/go/bin/go1.20.7 /home/asim/go/bin/go1.21.3" {
+		t.Errorf("identified %v, want each go1.* match", identified)
+	}
+}
+
+func TestEnforceBinariesResolvedPath(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	dir := t.TempDir()
+	cellar := filepath.Join(dir, "Cellar", "protobuf", "25.1", "bin", "protoc")
+	if err := os.MkdirAll(filepath.Dir(cellar), 0o755); err != nil {
+		t.Fatalf("failed to create Cellar: %v", err)
+	}
+	if err := os.WriteFile(cellar, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatalf("failed to write protoc: %v", err)
+	}
+	link := filepath.Join(dir, "bin", "protoc")
+	if err := os.MkdirAll(filepath.Dir(link), 0o755); err != nil {
+		t.Fatalf("failed to create bin: %v", err)
+	}
+	if err := os.Symlink(cellar, link); err != nil {
+		t.Fatalf("failed to link protoc: %v", err)
+	}
+
+	defer func(original func(context.Context, identifier.Program, identifier.IdentifyOptions, *zerolog.Logger) (identifier.Identification, error)) {
+		identifyWithOptions = original
+	}(identifyWithOptions)
+	identifyWithOptions = func(ctx context.Context, p identifier.Program, opts identifier.IdentifyOptions, zlog *zerolog.Logger) (identifier.Identification, error) {
+		return identifier.Identification{Version: "25.1", Path: link}, nil
+	}
+
+	cfg := &config.Config{Binary: []*config.Binary{{Name: "protoc", Version: "~3"}}}
+	results := enforceBinaries(context.Background(), cfg, &zlog)
+	if results[0].Status != StatusFail || results[0].ResolvedPath != link {
+		t.Fatalf("result = %+v, want a failure resolved to %s", results[0], link)
+	}
+
+	var jsonOut bytes.Buffer
+	if err := writeJSON(&jsonOut, results); err != nil {
+		t.Fatalf("writeJSON returned error: %v", err)
+	}
+	if want := fmt.Sprintf("%q: %q", "resolved_path", link); !strings.Contains(jsonOut.String(), want) {
+		t.Errorf("writeJSON() = %s, want it to contain %s", jsonOut.String(), want)
+	}
+
+	// The temporary directory may itself be under a symlink, e.g. on macOS.
+	target, err := filepath.EvalSymlinks(cellar)
+	if err != nil {
+		t.Fatalf("failed to resolve %s: %v", cellar, err)
+	}
+	var text bytes.Buffer
+	writeText(&text, results)
+	if want := "Path:\033[0m " + link + " -> " + target + "\n"; !strings.Contains(text.String(), want) {
+		t.Errorf("writeText() = %q, want it to contain %q", text.String(), want)
+	}
+}
+
+func TestEnforceBinariesAbsent(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	defer func(original func(string) (string, error)) { lookPath = original }(lookPath)
+	lookPath = func(file string) (string, error) {
+		if file == "telnet" {
+			return "/usr/bin/telnet", nil
+		}
+		return "", &exec.Error{Name: file, Err: exec.ErrNotFound}
+	}
+	defer func(original func(string) ([]string, error)) { findExecutables = original }(findExecutables)
+	findExecutables = func(pattern string) ([]string, error) {
+		return nil, nil
+	}
+
+	cfg := &config.Config{Binary: []*config.Binary{
+		{Name: "telnet", Absent: true, InstallHint: "remove with: apt-get remove telnet"},
+		{Name: "ftp", Absent: true},
+		{Name: "python2*", Absent: true},
+	}}
+	results := enforceBinaries(context.Background(), cfg, &zlog)
+	if len(results) != 3 {
+		t.Fatalf("enforceBinaries returned %d results, want 3", len(results))
+	}
+
+	telnet := results[0]
+	if telnet.Status != StatusFail || telnet.ResolvedPath != "/usr/bin/telnet" || !strings.Contains(telnet.Error, "/usr/bin/telnet") {
+		t.Errorf("telnet result = %+v, want a failure with its path", telnet)
+	}
+	if telnet.InstallHint != "remove with: apt-get remove telnet" {
+		t.Errorf("telnet install hint = %q, want the configured hint", telnet.InstallHint)
+	}
+	for _, result := range results[1:] {
+		if result.Status != StatusPass || !result.Satisfied {
+			t.Errorf("%s result = %+v, want a pass", result.Name, result)
+		}
+		if want := result.Name + " is absent as required"; result.message() != want {
+			t.Errorf("message() = %q, want %q", result.message(), want)
+		}
+	}
+	if code := exitCodeForResults(results); code != ExitVersionMismatch {
+		t.Errorf("exit code = %d, want %d", code, ExitVersionMismatch)
+	}
+
+	// Absent binaries are left out of a lock rather than making it incomplete.
+	if lock, unidentified := lockFromResults(results[1:]); len(lock) != 0 || len(unidentified) != 0 {
+		t.Errorf("lockFromResults() = %v, %v, want an empty lock", lock, unidentified)
+	}
+}
+
+func TestEnforceBinariesSkip(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	defer func(original []string) { skip = original }(skip)
+	skip = []string{"protoc"}
+	t.Setenv(skipEnv, "buf, helm")
+
+	var identified []string
+	defer func(original func(context.Context, identifier.Program, identifier.IdentifyOptions, *zerolog.Logger) (identifier.Identification, error)) {
+		identifyWithOptions = original
+	}(identifyWithOptions)
+	identifyWithOptions = func(ctx context.Context, p identifier.Program, opts identifier.IdentifyOptions, zlog *zerolog.Logger) (identifier.Identification, error) {
+		name := identifier.GetProgramName(p)
+		identified = append(identified, name)
+		if name != "go" {
+			return identifier.Identification{}, identifier.ErrProgramNotInstalled
+		}
+		return identifier.Identification{Version: "1.21.3"}, nil
+	}
+
+	cfg := &config.Config{Binary: []*config.Binary{
+		{Name: "go", Version: "~1.21"},
+		{Name: "protoc", Version: "~3"},
+		{Name: "buf", Version: "~1"},
+		{Name: "helm", Version: "~3"},
+	}}
+	results := enforceBinaries(context.Background(), cfg, &zlog)
+	if len(results) != 1 || results[0].Name != "go" {
+		t.Errorf("results = %+v, want only go", results)
+	}
+	if strings.Join(identified, " ") != "go" {
+		t.Errorf("identified %v, want only go", identified)
+	}
+	if code := exitCodeForResults(results); code != ExitSuccess {
+		t.Errorf("exit code = %d, want %d", code, ExitSuccess)
+	}
+}
+
+func TestRunEnforceLogsToStderr(t *testing.T) {
+	defer func(c, f string, v bool, level zerolog.Level) {
+		cfgFile, format, verbose = c, f, v
+		zerolog.SetGlobalLevel(level)
+	}(cfgFile, format, verbose, zerolog.GlobalLevel())
+	cfgFile = filepath.Join(t.TempDir(), "version-enforcer.hcl")
+	format, verbose = FormatJSON, true
+	if err := os.WriteFile(cfgFile, []byte("binary \"go\" {\n  version = \"~1.21\"\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(original func(context.Context, identifier.Program, identifier.IdentifyOptions, *zerolog.Logger) (identifier.Identification, error)) {
+		identifyWithOptions = original
+	}(identifyWithOptions)
+	identifyWithOptions = func(ctx context.Context, p identifier.Program, opts identifier.IdentifyOptions, zlog *zerolog.Logger) (identifier.Identification, error) {
+		return identifier.Identification{Version: "1.21.3"}, nil
+	}
+
+	logFile, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer logFile.Close()
+	defer func(original *os.File) { os.Stderr = original }(os.Stderr)
+	os.Stderr = logFile
+	zlog := newLogger()
+
+	var stdout, stderr bytes.Buffer
+	if code := runEnforce(context.Background(), &stdout, &stderr, &zlog); code != ExitSuccess {
+		t.Errorf("runEnforce() = %d, want %d", code, ExitSuccess)
+	}
+	var results []Result
+	if err := json.Unmarshal(stdout.Bytes(), &results); err != nil {
+		t.Errorf("stdout = %q, want only JSON results: %v", stdout.String(), err)
+	}
+	logs, err := os.ReadFile(logFile.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(logs, []byte("loaded config")) {
+		t.Errorf("stderr = %q, want the debug logs", logs)
+	}
+}
+
+func TestEnforceBinariesWorkingDir(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	// The fake tool reports the version pinned in the directory it is run in, like a version
+	// manager's shim.
+	dir := t.TempDir()
+	probe := filepath.Join(dir, "probe.sh")
+	if err := os.WriteFile(probe, []byte("#!/bin/sh\ncat .tool-version\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	project := filepath.Join(dir, "project")
+	if err := os.Mkdir(project, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(project, ".tool-version"), []byte("1.2.3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.Config{Binary: []*config.Binary{
+		{Name: "mytool", Version: "1.2.3", Probe: probe, WorkingDir: project},
+		{Name: "mytool", Version: "1.2.3", Probe: probe, WorkingDir: dir},
+	}}
+	results := enforceBinaries(context.Background(), cfg, &zlog)
+	if results[0].Status != StatusPass || results[0].Installed != "1.2.3" {
+		t.Errorf("result in %s = %+v, want a pass with 1.2.3", project, results[0])
+	}
+	if results[1].Status == StatusPass {
+		t.Errorf("result in %s = %+v, want a failure without a pinned version", dir, results[1])
+	}
+}
+
+func TestEnforceBinariesLatestSource(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	defer func(original func(context.Context, identifier.Program, identifier.IdentifyOptions, *zerolog.Logger) (identifier.Identification, error)) {
+		identifyWithOptions = original
+	}(identifyWithOptions)
+	identifyWithOptions = func(ctx context.Context, p identifier.Program, opts identifier.IdentifyOptions, zlog *zerolog.Logger) (identifier.Identification, error) {
+		return identifier.Identification{Version: "1.22.0"}, nil
+	}
+
+	// Serve the latest release like a real source would, so that fetching is exercised too.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, strings.TrimPrefix(r.URL.Path, "/"))
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{Binary: []*config.Binary{
+		{Name: "go", Version: ">= 1.21", LatestSource: server.URL + "/v1.23.1"},
+		{Name: "go", Version: ">= 1.21", LatestSource: server.URL + "/v1.23.1", OnOutdated: config.OnOutdatedError},
+		{Name: "go", Version: ">= 1.21", LatestSource: server.URL + "/1.22.0", OnOutdated: config.OnOutdatedError},
+		{Name: "go", Version: ">= 1.21", LatestSource: server.URL + "/", OnOutdated: config.OnOutdatedError},
+	}}
+	results := enforceBinaries(context.Background(), cfg, &zlog)
+	expected := []string{StatusPass, StatusFail, StatusPass, StatusPass}
+	for i, result := range results {
+		if result.Status != expected[i] {
+			t.Errorf("result %d = %+v, want status %s", i, result, expected[i])
+		}
+	}
+	if want := "older than the latest release 1.23.1"; results[1].Error != want {
+		t.Errorf("result 1 error = %q, want %q", results[1].Error, want)
+	}
+	if want := []string{"go version 1.22.0 is older than the latest release 1.23.1"}; !reflect.DeepEqual(results[0].Warnings, want) {
+		t.Errorf("result 0 warnings = %q, want %q", results[0].Warnings, want)
+	}
+	if len(results[2].Warnings) != 0 {
+		t.Errorf("result 2 warnings = %q, want none", results[2].Warnings)
+	}
+}
+
+func TestRunEnforceWarningsAsErrors(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	defer func(c, f string, w bool) {
+		cfgFile, format, warningsAsErrors = c, f, w
+	}(cfgFile, format, warningsAsErrors)
+	cfgFile = filepath.Join(t.TempDir(), "version-enforcer.hcl")
+	format = FormatText
+	contents := "binary \"go\" {\n  version = \"~1.21\"\n}\n\nbinary \"protoc\" {\n  version  = \"~3.20\"\n  optional = true\n}\n"
+	if err := os.WriteFile(cfgFile, []byte(contents), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(original func(context.Context, identifier.Program, identifier.IdentifyOptions, *zerolog.Logger) (identifier.Identification, error)) {
+		identifyWithOptions = original
+	}(identifyWithOptions)
+	identifyWithOptions = func(ctx context.Context, p identifier.Program, opts identifier.IdentifyOptions, zlog *zerolog.Logger) (identifier.Identification, error) {
+		if p == identifier.Protobuf {
+			return identifier.Identification{Version: "3.19.1"}, nil
+		}
+		return identifier.Identification{Version: "1.21.3"}, nil
+	}
+
+	const warning = "protoc version 3.19.1 is older than required by ~3.20 (1 minor version behind) (optional)"
+	for _, tt := range []struct {
+		warningsAsErrors bool
+		want             int
+		prefix           string
+	}{
+		{false, ExitSuccess, "Warning:"},
+		{true, ExitVersionMismatch, "Error:"},
+	} {
+		warningsAsErrors = tt.warningsAsErrors
+		var stdout, stderr bytes.Buffer
+		if code := runEnforce(context.Background(), &stdout, &stderr, &zlog); code != tt.want {
+			t.Errorf("runEnforce() with warningsAsErrors = %t = %d, want %d", tt.warningsAsErrors, code, tt.want)
+		}
+		if !strings.Contains(stdout.String(), tt.prefix+"\033[0m "+warning) {
+			t.Errorf("stdout with warningsAsErrors = %t = %q, want %s %q", tt.warningsAsErrors, stdout.String(), tt.prefix, warning)
+		}
+	}
+}
+
+func TestRunEnforceOutputFileError(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	defer func(c, o string) { cfgFile, outputFile = c, o }(cfgFile, outputFile)
+	cfgFile = filepath.Join(t.TempDir(), "version-enforcer.hcl")
+	outputFile = filepath.Join(t.TempDir(), "missing", "results.json")
+	if err := os.WriteFile(cfgFile, []byte("binary \"go\" {\n  version = \"~1.21\"\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	defer func(original func(context.Context, identifier.Program, identifier.IdentifyOptions, *zerolog.Logger) (identifier.Identification, error)) {
+		identifyWithOptions = original
+	}(identifyWithOptions)
+	identifyWithOptions = func(ctx context.Context, p identifier.Program, opts identifier.IdentifyOptions, zlog *zerolog.Logger) (identifier.Identification, error) {
+		return identifier.Identification{Version: "1.21.3"}, nil
+	}
+
+	// Failing to write the results is not a problem with the config.
+	var stdout, stderr bytes.Buffer
+	if code := runEnforce(context.Background(), &stdout, &stderr, &zlog); code != ExitInternalError {
+		t.Errorf("runEnforce() = %d, want %d", code, ExitInternalError)
+	}
+}
+
+func TestLoadConfigFromStdin(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	defer func(c, f string, r io.Reader) { cfgFile, cfgFormat, stdin = c, f, r }(cfgFile, cfgFormat, stdin)
+	cfgFile = stdinConfig
+	stdin = strings.NewReader("binary \"go\" {\n  version = \"~1.21\"\n}\n")
+	cfg, err := loadConfig(&zlog)
+	if err != nil || len(cfg.Binary) != 1 || cfg.Binary[0].Name != "go" {
+		t.Errorf("loadConfig() from stdin = %+v, %v, want go", cfg, err)
+	}
+
+	cfgFormat = config.FormatYAML
+	stdin = strings.NewReader("binary:\n  - name: cmake\n    version: \"~3.27\"\n")
+	cfg, err = loadConfig(&zlog)
+	if err != nil || len(cfg.Binary) != 1 || cfg.Binary[0].Name != "cmake" {
+		t.Errorf("loadConfig() from stdin with --config-format yaml = %+v, %v, want cmake", cfg, err)
+	}
+}
+
+func TestLoadConfigPins(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	if rootCmd.PersistentFlags().Lookup("pins") == nil || rootCmd.PersistentFlags().Lookup("lockfile") != nil {
+		t.Errorf("pins file flag is not --pins, which is distinct from the lockfile written by lock")
+	}
+
+	defer func(c, p string) { cfgFile, pinsFile = c, p }(cfgFile, pinsFile)
+	cfgFile = ""
+	pinsFile = filepath.Join(t.TempDir(), "versions.pins")
+	if err := os.WriteFile(pinsFile, []byte("# Pinned by the platform team.\ngo=1.21.3\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfig(&zlog)
+	if err != nil || len(cfg.Binary) != 1 || cfg.Binary[0].Name != "go" || cfg.Binary[0].Version != "1.21.3" {
+		t.Errorf("loadConfig() with --pins = %+v, %v, want go 1.21.3", cfg, err)
+	}
+}
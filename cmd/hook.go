@@ -0,0 +1,65 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"context"
+	"github.com/asimihsan/version-enforcer/command"
+	"github.com/rs/zerolog"
+	"io"
+	"strings"
+	"time"
+)
+
+// onFailureEnv lists the names of the binaries that did not pass, separated by commas, for the
+// --on-failure command.
+const onFailureEnv = "ENFORCE_FAILED"
+
+// onFailureTimeout is how long the --on-failure command may run before it is killed.
+const onFailureTimeout = time.Minute
+
+// runHook runs the --on-failure command. Tests replace it to check how it is run.
+var runHook = command.RunCommandWithEnv
+
+// runOnFailure runs the --on-failure command with sh if any result did not pass, with the names of
+// the binaries that did not pass as arguments and in $ENFORCE_FAILED, and writes its output to w.
+// A failing command is logged, but does not change the exit code.
+func runOnFailure(ctx context.Context, w io.Writer, results []Result, zlog *zerolog.Logger) {
+	if onFailure == "" {
+		return
+	}
+	var names []string
+	for _, result := range results {
+		if result.Status != StatusPass {
+			names = append(names, result.Name)
+		}
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, onFailureTimeout)
+	defer cancel()
+	env := []string{onFailureEnv + "=" + strings.Join(names, ",")}
+	args := append([]string{"-c", onFailure, "sh"}, names...)
+	output, err := runHook(ctx, env, "sh", args...)
+	io.WriteString(w, output.Stdout+output.Stderr)
+	if err != nil {
+		zlog.Error().Err(err).Str("command", onFailure).Msg("--on-failure command failed")
+	}
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"github.com/asimihsan/version-enforcer/command"
+	"github.com/rs/zerolog"
+	"strings"
+	"testing"
+)
+
+func TestRunOnFailure(t *testing.T) {
+	zlog := zerolog.Nop()
+	defer func(original string) { onFailure = original }(onFailure)
+	onFailure = `echo "missing: $@"`
+
+	var ran [][]string
+	defer func(original func(context.Context, []string, string, ...string) (command.Output, error)) {
+		runHook = original
+	}(runHook)
+	runHook = func(ctx context.Context, env []string, name string, arg ...string) (command.Output, error) {
+		ran = append(ran, append(append([]string{name}, arg...), env...))
+		return command.Output{Stdout: "missing: protoc buf\n"}, nil
+	}
+
+	passed := []Result{{Name: "go", Status: StatusPass}}
+	var w bytes.Buffer
+	runOnFailure(context.Background(), &w, passed, &zlog)
+	if len(ran) != 0 || w.Len() != 0 {
+		t.Errorf("runOnFailure() ran %v, want nothing to run when every binary passes", ran)
+	}
+
+	failed := append(passed, Result{Name: "protoc", Status: StatusMissing}, Result{Name: "buf", Status: StatusFail})
+	runOnFailure(context.Background(), &w, failed, &zlog)
+	want := []string{"sh", "-c", onFailure, "sh", "protoc", "buf", onFailureEnv + "=protoc,buf"}
+	if len(ran) != 1 || strings.Join(ran[0], "|") != strings.Join(want, "|") {
+		t.Errorf("runOnFailure() ran %q, want %q", ran, want)
+	}
+	if w.String() != "missing: protoc buf\n" {
+		t.Errorf("runOnFailure() wrote %q, want the output of the command", w.String())
+	}
+}
+
+func TestRunOnFailureShell(t *testing.T) {
+	zlog := zerolog.Nop()
+	defer func(original string) { onFailure = original }(onFailure)
+	onFailure = `echo "$# $1 $` + onFailureEnv + `"`
+
+	var w bytes.Buffer
+	runOnFailure(context.Background(), &w, []Result{{Name: "protoc", Status: StatusMissing}}, &zlog)
+	if want := "1 protoc protoc\n"; w.String() != want {
+		t.Errorf("runOnFailure() wrote %q, want %q", w.String(), want)
+	}
+}
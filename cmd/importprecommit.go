@@ -0,0 +1,47 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"github.com/asimihsan/version-enforcer/config"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var importPreCommitCmd = &cobra.Command{
+	Use:   "import-precommit <.pre-commit-config.yaml>",
+	Short: "Print a config requiring the programs pinned by pre-commit hook repos",
+	Long: `Print a config requiring the programs pinned by pre-commit hook repos.
+
+Hook repos whose rev is the version of a supported program, such as bufbuild/buf, become exact
+requirements. Other repos are skipped with a warning.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		zlog := newLogger()
+
+		cfg, err := config.LoadPreCommitConfig(args[0], &zlog)
+		if err != nil {
+			zlog.Error().Err(err).Str("path", args[0]).Msg("failed to load pre-commit config")
+			os.Exit(ExitConfigError)
+		}
+		if err := config.WriteRequirements(os.Stdout, cfg); err != nil {
+			zlog.Error().Err(err).Msg("failed to write config")
+			os.Exit(ExitInternalError)
+		}
+	},
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/asimihsan/version-enforcer/config"
+	"github.com/asimihsan/version-enforcer/identifier"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var (
+	initOutput string
+	initForce  bool
+)
+
+// identifyProgram identifies the installed version of a program. Tests replace it to avoid
+// depending on installed programs.
+var identifyProgram = identifier.IdentifyContext
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write a starter config pinning the installed version of every supported program",
+	Long: `Write a starter config pinning the installed version of every supported program.
+
+Every supported program is run to find its version, and programs that are not installed are
+skipped. The config is written to tool-enforcer.hcl unless --output is set, and an existing file
+is only replaced with --force.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		zlog := newLogger()
+
+		cfg := starterConfig(cmd.Context(), &zlog)
+		if err := config.SaveRequirements(initOutput, cfg, initForce); err != nil {
+			if errors.Is(err, os.ErrExist) {
+				zlog.Error().Str("path", initOutput).Msg("config already exists, use --force to replace it")
+			} else {
+				zlog.Error().Err(err).Str("path", initOutput).Msg("failed to write config")
+			}
+			os.Exit(ExitConfigError)
+		}
+		PrintSuccessLine(fmt.Sprintf("wrote %d binaries to %s", len(cfg.Binary), initOutput))
+	},
+}
+
+func init() {
+	initCmd.Flags().StringVar(&initOutput, "output", config.DefaultConfigPath, "config file to write")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "replace the config file if it exists")
+}
+
+// starterConfig returns a config that requires the installed version of every supported program
+// with a caret requirement. Programs that are not installed, or whose version cannot be
+// identified, are left out.
+func starterConfig(ctx context.Context, zlog *zerolog.Logger) *config.Config {
+	cfg := &config.Config{}
+	for _, p := range identifier.Programs() {
+		name := identifier.GetProgramName(p)
+		identification, err := identifyProgram(ctx, p, zlog)
+		if errors.Is(err, identifier.ErrProgramNotInstalled) {
+			zlog.Debug().Str("name", name).Msg("skipping program that is not installed")
+			continue
+		}
+		if err != nil {
+			zlog.Warn().Err(err).Str("name", name).Msg("skipping program whose version could not be identified")
+			continue
+		}
+
+		requirement := "^" + string(identification.Version)
+		if _, err := identifier.NewRequirement(requirement); err != nil {
+			zlog.Warn().Err(err).Str("name", name).Msg("skipping program whose version is not a valid requirement")
+			continue
+		}
+		cfg.Binary = append(cfg.Binary, &config.Binary{Name: name, Version: requirement})
+	}
+	return cfg
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/asimihsan/version-enforcer/identifier"
+	"github.com/rs/zerolog"
+	"testing"
+)
+
+func TestStarterConfig(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	defer func(original func(context.Context, identifier.Program, *zerolog.Logger) (identifier.Identification, error)) {
+		identifyProgram = original
+	}(identifyProgram)
+	identifyProgram = func(ctx context.Context, p identifier.Program, zlog *zerolog.Logger) (identifier.Identification, error) {
+		switch p {
+		case identifier.Git:
+			return identifier.Identification{Version: "2.39.1"}, nil
+		case identifier.Go:
+			return identifier.Identification{Version: "1.21.3"}, nil
+		case identifier.Bash:
+			return identifier.Identification{}, errors.New("no matches")
+		default:
+			return identifier.Identification{}, fmt.Errorf("%w: not found", identifier.ErrProgramNotInstalled)
+		}
+	}
+
+	cfg := starterConfig(context.Background(), &zlog)
+	if len(cfg.Binary) != 2 {
+		t.Fatalf("starterConfig has %d binaries, want 2: %+v", len(cfg.Binary), cfg.Binary)
+	}
+	if cfg.Binary[0].Name != "git" || cfg.Binary[0].Version != "^2.39.1" {
+		t.Errorf("starterConfig binary 0 = %+v, want git ^2.39.1", cfg.Binary[0])
+	}
+	if cfg.Binary[1].Name != "go" || cfg.Binary[1].Version != "^1.21.3" {
+		t.Errorf("starterConfig binary 1 = %+v, want go ^1.21.3", cfg.Binary[1])
+	}
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+)
+
+// junitTestSuite is a JUnit XML report with a test case per binary, as understood by CI systems
+// such as Jenkins.
+type junitTestSuite struct {
+	XMLName   xml.Name        `xml:"testsuite"`
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes results as a JUnit XML test suite. A binary whose version does not satisfy its
+// requirement is a failure, and a binary whose version could not be identified is an error. Warnings
+// are written to the test case's output.
+func writeJUnit(w io.Writer, results []Result) error {
+	suite := junitTestSuite{
+		Name:  "version-enforcer",
+		Tests: len(results),
+	}
+
+	for _, result := range results {
+		testCase := junitTestCase{
+			Name:      result.Name,
+			ClassName: "version-enforcer",
+		}
+		for _, warning := range result.Warnings {
+			testCase.SystemOut += "warning: " + warning + "\n"
+		}
+		problem := &junitProblem{
+			Message: result.message(),
+			Text:    fmt.Sprintf("required: %s\nfound: %s", result.Required, result.Installed),
+		}
+		if result.InstallHint != "" {
+			problem.Text += "\nhint: " + result.InstallHint
+		}
+
+		switch result.Status {
+		case StatusPass:
+		case StatusFail:
+			testCase.Failure = problem
+			suite.Failures++
+		default:
+			problem.Text = fmt.Sprintf("required: %s\nerror: %s", result.Required, result.Error)
+			if result.InstallHint != "" {
+				problem.Text += "\nhint: " + result.InstallHint
+			}
+			testCase.Error = problem
+			suite.Errors++
+		}
+		suite.TestCases = append(suite.TestCases, testCase)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suite); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/xml"
+	"strings"
+	"testing"
+)
+
+func TestWriteJUnit(t *testing.T) {
+	results := []Result{
+		{Name: "go", Required: "~1.21", Installed: "1.21.3", Satisfied: true, Status: StatusPass, Warnings: []string{"go version 1.21.3 is older than the latest release 1.22.0"}},
+		{Name: "git", Required: "~2", Installed: "3.0.0", Status: StatusFail},
+		{Name: "protoc", Required: "~3", Status: StatusMissing, Error: "executable file not found in $PATH"},
+	}
+
+	var buf bytes.Buffer
+	if err := writeJUnit(&buf, results); err != nil {
+		t.Fatalf("writeJUnit returned error: %v", err)
+	}
+
+	var suite junitTestSuite
+	if err := xml.Unmarshal(buf.Bytes(), &suite); err != nil {
+		t.Fatalf("failed to parse JUnit XML: %v\n%s", err, buf.String())
+	}
+	if suite.Tests != 3 || suite.Failures != 1 || suite.Errors != 1 {
+		t.Errorf("suite tests/failures/errors = %d/%d/%d, want 3/1/1", suite.Tests, suite.Failures, suite.Errors)
+	}
+	if suite.TestCases[0].Failure != nil || suite.TestCases[0].Error != nil {
+		t.Errorf("passing test case has a failure or error: %+v", suite.TestCases[0])
+	}
+	if want := "warning: go version 1.21.3 is older than the latest release 1.22.0\n"; suite.TestCases[0].SystemOut != want {
+		t.Errorf("passing test case output = %q, want %q", suite.TestCases[0].SystemOut, want)
+	}
+
+	failure := suite.TestCases[1].Failure
+	if failure == nil {
+		t.Fatalf("failing test case has no failure: %+v", suite.TestCases[1])
+	}
+	if !strings.Contains(failure.Text, "required: ~2") || !strings.Contains(failure.Text, "found: 3.0.0") {
+		t.Errorf("failure text = %q, want required and found versions", failure.Text)
+	}
+
+	if suite.TestCases[2].Error == nil {
+		t.Errorf("missing test case has no error: %+v", suite.TestCases[2])
+	}
+}
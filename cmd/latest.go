@@ -0,0 +1,57 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/asimihsan/version-enforcer/config"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+// latestTimeout is how long fetching the latest release of a binary may take.
+const latestTimeout = 10 * time.Second
+
+// checkLatest fetches the latest release of the binary from its latest_source, and returns a
+// message saying so if version is older, or an empty string if it is not. A latest release that
+// cannot be fetched or compared is logged rather than treated as outdated, so that an unreachable
+// source does not fail the check.
+func checkLatest(ctx context.Context, binary *config.Binary, version string, zlog *zerolog.Logger) string {
+	ctx, cancel := context.WithTimeout(ctx, latestTimeout)
+	defer cancel()
+	latest, err := fetchLatest(ctx, binary.LatestSource)
+	if err != nil {
+		zlog.Warn().Err(err).Str("name", binary.Name).Str("latest_source", binary.LatestSource).Msg("failed to fetch latest version")
+		return ""
+	}
+
+	comparator, err := binary.VersionComparator()
+	if err != nil {
+		return ""
+	}
+	c, err := comparator.Compare(version, string(latest))
+	if err != nil {
+		zlog.Warn().Err(err).Str("name", binary.Name).Str("latest", string(latest)).Msg("failed to compare with latest version")
+		return ""
+	}
+	if c >= 0 {
+		return ""
+	}
+	return fmt.Sprintf("older than the latest release %s", latest)
+}
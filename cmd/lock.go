@@ -0,0 +1,101 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/asimihsan/version-enforcer/config"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+var lockCmd = &cobra.Command{
+	Use:   "lock --config <config file>",
+	Short: "Write the detected versions of all configured binaries to a lockfile",
+	Long: `Write the detected versions of all configured binaries to a lockfile.
+
+Run with --locked afterwards to require exactly these versions.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		zlog := newLogger()
+
+		cfg, err := loadConfig(&zlog)
+		if err != nil {
+			zlog.Error().Err(err).Msg("failed to load config")
+			os.Exit(ExitConfigError)
+		}
+
+		results := enforceBinaries(cmd.Context(), cfg, &zlog)
+		lock, unidentified := lockFromResults(results)
+		if len(unidentified) > 0 {
+			writeText(os.Stdout, unidentified)
+			os.Exit(exitCodeForResults(unidentified))
+		}
+
+		if err := config.SaveLock(lockPath, lock); err != nil {
+			zlog.Error().Err(err).Str("path", lockPath).Msg("failed to write lockfile")
+			os.Exit(ExitInternalError)
+		}
+		PrintSuccessLine(fmt.Sprintf("wrote %d versions to %s", len(lock), lockPath))
+	},
+}
+
+// lockFromResults returns a lock of the installed versions in results, whether or not they satisfy
+// their requirements. It also returns the results whose version could not be identified, in which
+// case the lock is incomplete and should not be written.
+func lockFromResults(results []Result) (config.Lock, []Result) {
+	lock := make(config.Lock)
+	var unidentified []Result
+	for _, result := range results {
+		if result.Installed == "" && result.Status == StatusPass {
+			// A binary that must be absent passes without a version, so there is nothing to lock.
+			continue
+		}
+		if result.Installed == "" {
+			unidentified = append(unidentified, result)
+			continue
+		}
+		lock[result.Name] = result.Installed
+	}
+	return lock, unidentified
+}
+
+// checkLock fails every result whose installed version differs from the exact version recorded in
+// lock, or that is not in lock at all. This is stricter than checking the requirement, which must
+// also still be satisfied.
+func checkLock(results []Result, lock config.Lock) {
+	for i := range results {
+		result := &results[i]
+		if result.Installed == "" {
+			continue
+		}
+
+		lockedVersion, ok := lock[result.Name]
+		if !ok {
+			result.Satisfied = false
+			result.Status = StatusFail
+			result.Error = "not in lockfile"
+			continue
+		}
+
+		result.Locked = lockedVersion
+		if result.Installed != lockedVersion {
+			result.Satisfied = false
+			result.Status = StatusFail
+		}
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"github.com/asimihsan/version-enforcer/config"
+	"testing"
+)
+
+func TestLockFromResults(t *testing.T) {
+	results := []Result{
+		{Name: "go", Required: "~1.21", Installed: "1.21.3", Satisfied: true, Status: StatusPass},
+		{Name: "git", Required: "~2", Installed: "3.0.0", Status: StatusFail},
+	}
+
+	lock, unidentified := lockFromResults(results)
+	if len(unidentified) != 0 {
+		t.Errorf("lockFromResults returned unidentified results: %+v", unidentified)
+	}
+	if len(lock) != 2 || lock["go"] != "1.21.3" || lock["git"] != "3.0.0" {
+		t.Errorf("lockFromResults lock = %v", lock)
+	}
+
+	results = append(results, Result{Name: "protoc", Required: "~3", Status: StatusMissing})
+	_, unidentified = lockFromResults(results)
+	if len(unidentified) != 1 || unidentified[0].Name != "protoc" {
+		t.Errorf("lockFromResults unidentified = %+v, want protoc", unidentified)
+	}
+}
+
+func TestCheckLock(t *testing.T) {
+	lock := config.Lock{"go": "1.21.3", "git": "2.39.1"}
+
+	// A matching environment passes.
+	results := []Result{
+		{Name: "go", Required: "~1.21", Installed: "1.21.3", Satisfied: true, Status: StatusPass},
+		{Name: "git", Required: "~2", Installed: "2.39.1", Satisfied: true, Status: StatusPass},
+	}
+	checkLock(results, lock)
+	for _, result := range results {
+		if result.Status != StatusPass || result.Locked != lock[result.Name] {
+			t.Errorf("checkLock result = %+v, want pass locked at %s", result, lock[result.Name])
+		}
+	}
+	if code := exitCodeForResults(results); code != ExitSuccess {
+		t.Errorf("exit code = %d, want %d", code, ExitSuccess)
+	}
+
+	// A drifted environment fails even though the requirements are still satisfied.
+	results = []Result{
+		{Name: "go", Required: "~1.21", Installed: "1.21.4", Satisfied: true, Status: StatusPass},
+		{Name: "git", Required: "~2", Installed: "2.39.1", Satisfied: true, Status: StatusPass},
+		{Name: "make", Required: ">= 4.1", Installed: "4.3", Satisfied: true, Status: StatusPass},
+	}
+	checkLock(results, lock)
+	if results[0].Status != StatusFail || results[0].Locked != "1.21.3" {
+		t.Errorf("drifted go result = %+v, want fail locked at 1.21.3", results[0])
+	}
+	if results[1].Status != StatusPass {
+		t.Errorf("git result = %+v, want pass", results[1])
+	}
+	if results[2].Status != StatusFail || results[2].Error != "not in lockfile" {
+		t.Errorf("unlocked make result = %+v, want fail", results[2])
+	}
+	if code := exitCodeForResults(results); code != ExitVersionMismatch {
+		t.Errorf("exit code = %d, want %d", code, ExitVersionMismatch)
+	}
+}
@@ -0,0 +1,308 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// Output formats for results.
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatJUnit = "junit"
+	FormatTable = "table"
+	FormatCSV   = "csv"
+)
+
+// Ways to group results.
+const (
+	GroupByStatus   = "status"
+	GroupBySeverity = "severity"
+)
+
+// statusOrder is the order of statuses when grouping by status.
+var statusOrder = map[string]int{
+	StatusPass:    0,
+	StatusFail:    1,
+	StatusMissing: 2,
+	StatusError:   3,
+}
+
+// validateGroupBy returns an error if groupBy is not a known way to group results. An empty
+// groupBy means results are not grouped.
+func validateGroupBy(groupBy string) error {
+	switch groupBy {
+	case "", GroupByStatus, GroupBySeverity:
+		return nil
+	default:
+		return fmt.Errorf("unknown group-by %q", groupBy)
+	}
+}
+
+// groupResults returns a copy of results with results of the same status next to each other,
+// keeping the config order within each group. Grouping by status orders the groups pass, fail,
+// missing, then error, and grouping by severity orders them by the severity of their exit code,
+// most severe first, so that failures come before passes.
+func groupResults(results []Result, groupBy string) []Result {
+	grouped := append([]Result(nil), results...)
+	switch groupBy {
+	case GroupByStatus:
+		sort.SliceStable(grouped, func(i, j int) bool {
+			return statusOrder[grouped[i].Status] < statusOrder[grouped[j].Status]
+		})
+	case GroupBySeverity:
+		sort.SliceStable(grouped, func(i, j int) bool {
+			return grouped[i].exitCode() > grouped[j].exitCode()
+		})
+	}
+	return grouped
+}
+
+// validateFormat returns an error if format is not a known output format.
+func validateFormat(format string) error {
+	switch format {
+	case FormatText, FormatJSON, FormatJUnit, FormatTable, FormatCSV:
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// writeResults writes results to w in the given format.
+func writeResults(w io.Writer, results []Result, format string) error {
+	switch format {
+	case FormatText:
+		writeText(w, results)
+		return nil
+	case FormatJSON:
+		return writeJSON(w, results)
+	case FormatJUnit:
+		return writeJUnit(w, results)
+	case FormatTable:
+		return writeTable(w, results)
+	case FormatCSV:
+		return writeCSV(w, results)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// writeText writes a line per failed result, followed by the path of the executable that was
+// checked if its version was wrong, and its install hint if it has one. Passing results are only
+// written if they have warnings, in verbose mode, or with --explain. Verbose mode also writes how
+// each version was found.
+func writeText(w io.Writer, results []Result) {
+	for _, result := range results {
+		switch result.Status {
+		case StatusPass:
+			if len(result.Warnings) == 0 && !verbose && !explain {
+				continue
+			}
+			if len(result.Warnings) == 0 {
+				fprintSuccessLine(w, result.message())
+			}
+			for _, warning := range result.Warnings {
+				fprintWarningLine(w, warning)
+			}
+		default:
+			fprintErrorLine(w, result.message())
+		}
+		if verbose && result.Method != "" {
+			fprintMethodLine(w, result.Method)
+		}
+		if result.Note != "" {
+			fprintNoteLine(w, result.Note)
+		}
+		if result.Explanation != "" {
+			for _, line := range strings.Split(result.Explanation, "\n") {
+				fprintExplanationLine(w, line)
+			}
+		}
+		if result.Status == StatusPass {
+			continue
+		}
+		if result.Status == StatusFail && result.ResolvedPath != "" {
+			fprintPathLine(w, result.ResolvedPath)
+		}
+		if result.InstallHint != "" {
+			fprintHintLine(w, result.InstallHint)
+		}
+	}
+}
+
+// writeTable writes every result as a row of an aligned table. The status column is colored, and
+// is last so that its escape codes do not affect the alignment of the other columns.
+func writeTable(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROGRAM\tREQUIRED\tINSTALLED\tSTATUS")
+	for _, result := range results {
+		installed := result.Installed
+		if installed == "" {
+			installed = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", result.Name, result.Required, installed, colorStatus(result.Status))
+	}
+	return tw.Flush()
+}
+
+// colorStatus returns status in green if it passed, yellow if the program is missing, and red
+// otherwise.
+func colorStatus(status string) string {
+	switch status {
+	case StatusPass:
+		return fmt.Sprintf("\033[32;1m%s\033[0m", status)
+	case StatusMissing:
+		return fmt.Sprintf("\033[33;1m%s\033[0m", status)
+	default:
+		return fmt.Sprintf("\033[31;1m%s\033[0m", status)
+	}
+}
+
+// writeCSV writes a header row and a row per result, e.g. for importing into a spreadsheet.
+func writeCSV(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"program", "required", "installed", "satisfied", "error"}); err != nil {
+		return err
+	}
+	for _, result := range results {
+		row := []string{result.Name, result.Required, result.Installed, strconv.FormatBool(result.Satisfied), result.Error}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func writeJSON(w io.Writer, results []Result) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(results)
+}
+
+// failuresAndWarnings returns the results that did not pass, and those that passed with warnings,
+// which are still worth reporting when passing results are left out.
+func failuresAndWarnings(results []Result) []Result {
+	failed := make([]Result, 0, len(results))
+	for _, result := range results {
+		if result.Status != StatusPass || len(result.Warnings) > 0 {
+			failed = append(failed, result)
+		}
+	}
+	return failed
+}
+
+// Summary counts the results that passed and failed.
+type Summary struct {
+	Total  int `json:"total"`
+	Passed int `json:"passed"`
+	Failed int `json:"failed"`
+}
+
+// summarize counts the results that passed and failed.
+func summarize(results []Result) Summary {
+	summary := Summary{Total: len(results)}
+	for _, result := range results {
+		if result.Status == StatusPass {
+			summary.Passed++
+		}
+	}
+	summary.Failed = summary.Total - summary.Passed
+	return summary
+}
+
+// validateSummaryFormat returns an error if format is not a known summary format. An empty format
+// means no summary.
+func validateSummaryFormat(format string) error {
+	switch format {
+	case "", FormatText, FormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("unknown summary format %q", format)
+	}
+}
+
+// writeOutput writes results to stdout in --format, leaving out passing results without warnings
+// if --only-failures or --quiet is set, and writing nothing at all if --quiet is set and every
+// result passed without warnings. The summary is written to stderr in --summary-format if set, so that it stays visible
+// when stdout is piped, and otherwise to stdout in text format when watching unless --quiet is
+// set. The summary always counts every result.
+func writeOutput(stdout, stderr io.Writer, results []Result) error {
+	written := groupResults(results, groupBy)
+	if onlyFailures || quiet {
+		written = failuresAndWarnings(written)
+	}
+	if !quiet || len(written) > 0 {
+		if err := writeResults(stdout, written, format); err != nil {
+			return err
+		}
+	}
+	switch {
+	case summaryFormat != "":
+		return writeSummary(stderr, results, summaryFormat)
+	case watchConfig && !quiet:
+		return writeSummary(stdout, results, FormatText)
+	default:
+		return nil
+	}
+}
+
+// writeOutputFile is like writeOutput, but writes results to the file at path, truncating it,
+// instead of stdout, e.g. for CI to upload. A text summary is still written to stdout unless
+// --quiet is set, so that the run is visible in logs.
+func writeOutputFile(path string, stdout, stderr io.Writer, results []Result) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeOutput(f, stderr, results); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if quiet {
+		return nil
+	}
+	return writeSummary(stdout, results, FormatText)
+}
+
+// writeSummary writes the number of passed and failed results to w in the given format.
+func writeSummary(w io.Writer, results []Result, format string) error {
+	summary := summarize(results)
+	switch format {
+	case FormatText:
+		_, err := fmt.Fprintf(w, "%d of %d binaries satisfy their requirements, %d failed\n", summary.Passed, summary.Total, summary.Failed)
+		return err
+	case FormatJSON:
+		return json.NewEncoder(w).Encode(summary)
+	default:
+		return fmt.Errorf("unknown summary format %q", format)
+	}
+}
@@ -0,0 +1,342 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteOutputSummaryFormat(t *testing.T) {
+	defer func(f, s string) { format, summaryFormat = f, s }(format, summaryFormat)
+	format, summaryFormat = FormatJSON, FormatText
+
+	results := []Result{
+		{Name: "go", Required: "~1.21", Installed: "1.21.3", Satisfied: true, Status: StatusPass},
+		{Name: "git", Required: "~2", Installed: "3.0.0", Status: StatusFail},
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := writeOutput(&stdout, &stderr, results); err != nil {
+		t.Fatalf("writeOutput returned error: %v", err)
+	}
+
+	var written []Result
+	if err := json.Unmarshal(stdout.Bytes(), &written); err != nil {
+		t.Fatalf("stdout is not JSON results: %v\n%s", err, stdout.String())
+	}
+	if len(written) != 2 {
+		t.Errorf("stdout has %d results, want 2", len(written))
+	}
+
+	want := "1 of 2 binaries satisfy their requirements, 1 failed\n"
+	if stderr.String() != want {
+		t.Errorf("stderr = %q, want %q", stderr.String(), want)
+	}
+}
+
+func TestWriteOutputFile(t *testing.T) {
+	defer func(f, s string) { format, summaryFormat = f, s }(format, summaryFormat)
+	format, summaryFormat = FormatJSON, ""
+
+	results := []Result{
+		{Name: "go", Required: "~1.21", Installed: "1.21.3", Satisfied: true, Status: StatusPass},
+		{Name: "git", Required: "~2", Installed: "3.0.0", Status: StatusFail},
+	}
+
+	// An existing file is truncated rather than appended to.
+	path := filepath.Join(t.TempDir(), "results.json")
+	if err := os.WriteFile(path, bytes.Repeat([]byte("x"), 4096), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr bytes.Buffer
+	if err := writeOutputFile(path, &stdout, &stderr, results); err != nil {
+		t.Fatalf("writeOutputFile returned error: %v", err)
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var written []Result
+	if err := json.Unmarshal(contents, &written); err != nil {
+		t.Fatalf("%s is not JSON results: %v\n%s", path, err, contents)
+	}
+	if len(written) != 2 || written[1].Name != "git" {
+		t.Errorf("%s results = %+v, want go and git", path, written)
+	}
+	if want := "1 of 2 binaries satisfy their requirements, 1 failed\n"; stdout.String() != want {
+		t.Errorf("stdout = %q, want %q", stdout.String(), want)
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing", "results.json")
+	if err := writeOutputFile(missing, &stdout, &stderr, results); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("writeOutputFile(%s) error = %v, want %v", missing, err, fs.ErrNotExist)
+	}
+}
+
+func TestWriteOutputOnlyFailures(t *testing.T) {
+	defer func(f, s string, o bool) { format, summaryFormat, onlyFailures = f, s, o }(format, summaryFormat, onlyFailures)
+	format, summaryFormat, onlyFailures = FormatJSON, FormatJSON, true
+
+	results := []Result{
+		{Name: "go", Required: "~1.21", Installed: "1.21.3", Satisfied: true, Status: StatusPass},
+		{Name: "git", Required: "~2", Installed: "3.0.0", Status: StatusFail},
+		{Name: "protoc", Required: "~3", Status: StatusError, Error: "no matches"},
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := writeOutput(&stdout, &stderr, results); err != nil {
+		t.Fatalf("writeOutput returned error: %v", err)
+	}
+
+	var written []Result
+	if err := json.Unmarshal(stdout.Bytes(), &written); err != nil {
+		t.Fatalf("stdout is not JSON results: %v\n%s", err, stdout.String())
+	}
+	if len(written) != 2 || written[0].Name != "git" || written[1].Name != "protoc" {
+		t.Errorf("stdout results = %+v, want only git and protoc", written)
+	}
+
+	var summary Summary
+	if err := json.Unmarshal(stderr.Bytes(), &summary); err != nil {
+		t.Fatalf("stderr is not a JSON summary: %v\n%s", err, stderr.String())
+	}
+	want := Summary{Total: 3, Passed: 1, Failed: 2}
+	if summary != want {
+		t.Errorf("summary = %+v, want %+v", summary, want)
+	}
+}
+
+func TestWriteSummaryJSON(t *testing.T) {
+	results := []Result{
+		{Name: "go", Status: StatusPass},
+		{Name: "protoc", Status: StatusMissing},
+		{Name: "git", Status: StatusFail},
+	}
+
+	var buf bytes.Buffer
+	if err := writeSummary(&buf, results, FormatJSON); err != nil {
+		t.Fatalf("writeSummary returned error: %v", err)
+	}
+
+	var summary Summary
+	if err := json.Unmarshal(buf.Bytes(), &summary); err != nil {
+		t.Fatalf("summary is not JSON: %v\n%s", err, buf.String())
+	}
+	want := Summary{Total: 3, Passed: 1, Failed: 2}
+	if summary != want {
+		t.Errorf("summary = %+v, want %+v", summary, want)
+	}
+}
+
+func TestWriteTable(t *testing.T) {
+	results := []Result{
+		{Name: "go", Required: "~1.21", Installed: "1.21.3", Satisfied: true, Status: StatusPass},
+		{Name: "protoc", Required: "~3", Status: StatusMissing},
+	}
+
+	var buf bytes.Buffer
+	if err := writeTable(&buf, results); err != nil {
+		t.Fatalf("writeTable returned error: %v", err)
+	}
+
+	want := "PROGRAM  REQUIRED  INSTALLED  STATUS\n" +
+		"go       ~1.21     1.21.3     \033[32;1mpass\033[0m\n" +
+		"protoc   ~3        -          \033[33;1mmissing\033[0m\n"
+	if buf.String() != want {
+		t.Errorf("writeTable() =\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	results := []Result{
+		{Name: "go", Required: "~1.21", Installed: "1.21.3", Satisfied: true, Status: StatusPass},
+		{Name: "protoc", Required: ">=3 <4", Status: StatusError, Error: "no matches, output was \"libprotoc\""},
+	}
+
+	var buf bytes.Buffer
+	if err := writeCSV(&buf, results); err != nil {
+		t.Fatalf("writeCSV returned error: %v", err)
+	}
+	if want := `"no matches, output was ""libprotoc"""`; !strings.Contains(buf.String(), want) {
+		t.Errorf("writeCSV() = %q, want the error quoted as %s", buf.String(), want)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	want := [][]string{
+		{"program", "required", "installed", "satisfied", "error"},
+		{"go", "~1.21", "1.21.3", "true", ""},
+		{"protoc", ">=3 <4", "", "false", "no matches, output was \"libprotoc\""},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("writeCSV() wrote %d records, want %d", len(records), len(want))
+	}
+	for i := range want {
+		if strings.Join(records[i], "|") != strings.Join(want[i], "|") {
+			t.Errorf("record %d = %q, want %q", i, records[i], want[i])
+		}
+	}
+}
+
+func TestWriteOutputQuiet(t *testing.T) {
+	defer func(f string, q, v bool) { format, quiet, verbose = f, q, v }(format, quiet, verbose)
+	quiet, verbose = true, false
+
+	results := []Result{
+		{Name: "go", Required: "~1.21", Installed: "1.21.3", Satisfied: true, Status: StatusPass},
+		{Name: "git", Required: "~2", Installed: "2.39.1", Satisfied: true, Status: StatusPass},
+	}
+	for _, f := range []string{FormatText, FormatJSON, FormatTable} {
+		format = f
+		var stdout, stderr bytes.Buffer
+		if err := writeOutput(&stdout, &stderr, results); err != nil {
+			t.Fatalf("writeOutput returned error: %v", err)
+		}
+		if stdout.Len() != 0 || stderr.Len() != 0 {
+			t.Errorf("--format %s --quiet wrote %q to stdout and %q to stderr, want nothing", f, stdout.String(), stderr.String())
+		}
+	}
+
+	format = FormatText
+	results = append(results, Result{Name: "protoc", Required: "~3", Status: StatusError, Error: "no matches"})
+	var stdout, stderr bytes.Buffer
+	if err := writeOutput(&stdout, &stderr, results); err != nil {
+		t.Fatalf("writeOutput returned error: %v", err)
+	}
+	if !bytes.Contains(stdout.Bytes(), []byte("protoc")) || bytes.Contains(stdout.Bytes(), []byte("go version")) {
+		t.Errorf("--quiet stdout = %q, want only the protoc failure", stdout.String())
+	}
+}
+
+func TestWriteTextExplain(t *testing.T) {
+	defer func(e, v bool) { explain, verbose = e, v }(explain, verbose)
+	explain, verbose = true, false
+
+	results := []Result{
+		{
+			Name: "go", Required: "~1.21", Installed: "1.21.3", Satisfied: true, Status: StatusPass,
+			Explanation: "requirement ~1.21 parsed as tilde; found 1.21.3, which is in range → satisfied",
+		},
+		{
+			Name: "git", Required: "=2.38.0 || =2.39.0", Installed: "2.40.0", Status: StatusFail,
+			Explanation: "requirement =2.38.0 parsed as equal\nrequirement =2.39.0 parsed as equal",
+		},
+	}
+	var buf bytes.Buffer
+	writeText(&buf, results)
+	for _, want := range []string{
+		"go version 1.21.3 satisfies requirement ~1.21",
+		"Explain:\033[0m requirement ~1.21 parsed as tilde",
+		"Explain:\033[0m requirement =2.38.0 parsed as equal\n",
+		"Explain:\033[0m requirement =2.39.0 parsed as equal\n",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("writeText() = %q, want it to contain %q", buf.String(), want)
+		}
+	}
+}
+
+func TestGroupResults(t *testing.T) {
+	results := []Result{
+		{Name: "go", Status: StatusPass},
+		{Name: "protoc", Status: StatusError},
+		{Name: "git", Status: StatusFail},
+		{Name: "make", Status: StatusPass},
+		{Name: "buf", Status: StatusMissing},
+		{Name: "helm", Status: StatusFail},
+	}
+	tests := []struct {
+		groupBy  string
+		expected []string
+	}{
+		{"", []string{"go", "protoc", "git", "make", "buf", "helm"}},
+		{GroupByStatus, []string{"go", "make", "git", "helm", "buf", "protoc"}},
+		{GroupBySeverity, []string{"protoc", "buf", "git", "helm", "go", "make"}},
+	}
+	for _, test := range tests {
+		grouped := groupResults(results, test.groupBy)
+		names := make([]string, len(grouped))
+		for i, result := range grouped {
+			names[i] = result.Name
+		}
+		if strings.Join(names, " ") != strings.Join(test.expected, " ") {
+			t.Errorf("groupResults(%q) = %v, want %v", test.groupBy, names, test.expected)
+		}
+	}
+	if results[1].Name != "protoc" {
+		t.Errorf("groupResults modified its argument: %v", results)
+	}
+}
+
+func TestWriteTextVerboseMethod(t *testing.T) {
+	defer func(v bool) { verbose = v }(verbose)
+	results := []Result{
+		{Name: "go", Required: "~1.21", Installed: "1.21.3", Satisfied: true, Status: StatusPass, Method: "built-in `go version`"},
+	}
+
+	var buf bytes.Buffer
+	verbose = false
+	writeText(&buf, results)
+	if buf.Len() != 0 {
+		t.Errorf("writeText() = %q, want nothing without --verbose", buf.String())
+	}
+
+	verbose = true
+	writeText(&buf, results)
+	if want := "Via:\033[0m built-in `go version`\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("writeText() = %q, want it to contain %q", buf.String(), want)
+	}
+}
+
+func TestWriteTextFailedWithWarnings(t *testing.T) {
+	incompatible := "protoc 25.1 is incompatible with protoc-gen-go 1.28.1"
+	results := []Result{
+		{Name: "protoc", Required: "^25.1", Installed: "25.0", Status: StatusFail, Reason: ReasonTooOld, Warnings: []string{incompatible}},
+		{Name: "go", Required: "~1.21", Installed: "1.21.3", Locked: "1.21.2", Status: StatusFail, Warnings: []string{"go version 1.21.3 is older than the latest release 1.22.0"}},
+	}
+
+	// A result that failed anyway reports why, followed by its warnings.
+	var buf bytes.Buffer
+	writeText(&buf, results)
+	for _, want := range []string{
+		"protoc version 25.0 is older than required by ^25.1; " + incompatible,
+		"go version 1.21.3 differs from locked version 1.21.2; go version 1.21.3 is older than the latest release 1.22.0",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("writeText() = %q, want it to contain %q", buf.String(), want)
+		}
+	}
+
+	// A result that only failed because of --warnings-as-errors reports just its warnings.
+	results = []Result{{Name: "protoc", Required: "^25.1", Installed: "25.1", Satisfied: true, Status: StatusPass, Warnings: []string{incompatible}}}
+	promoteWarnings(results)
+	if got := results[0].message(); got != incompatible {
+		t.Errorf("message() with warnings as errors = %q, want %q", got, incompatible)
+	}
+}
@@ -0,0 +1,151 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/asimihsan/version-enforcer/identifier"
+	"strings"
+)
+
+// Statuses of a Result.
+const (
+	StatusPass    = "pass"
+	StatusFail    = "fail"
+	StatusMissing = "missing"
+	StatusError   = "error"
+)
+
+// Reasons that a Result failed because of its installed version.
+const (
+	ReasonTooOld = "too_old"
+	ReasonTooNew = "too_new"
+)
+
+// Result is the outcome of enforcing the requirement of a single binary.
+type Result struct {
+	Name         string   `json:"name"`
+	Required     string   `json:"required"`
+	Installed    string   `json:"installed,omitempty"`
+	Commit       string   `json:"commit,omitempty"`
+	ResolvedPath string   `json:"resolved_path,omitempty"`
+	Locked       string   `json:"locked,omitempty"`
+	Satisfied    bool     `json:"satisfied"`
+	Status       string   `json:"status"`
+	Reason       string   `json:"reason,omitempty"`
+	Gap          string   `json:"gap,omitempty"`
+	Explanation  string   `json:"explanation,omitempty"`
+	Note         string   `json:"note,omitempty"`
+	Method       string   `json:"method,omitempty"`
+	Error        string   `json:"error,omitempty"`
+	InstallHint  string   `json:"install_hint,omitempty"`
+	Warnings     []string `json:"warnings,omitempty"`
+
+	// program is the supported program that the binary was identified as, which may differ from
+	// its name, or nil if it is not one.
+	program *identifier.Program
+
+	// promoted is set if the result passed, but --warnings-as-errors failed it for its warnings.
+	promoted bool
+}
+
+// message returns a human-readable description of the result.
+func (r Result) message() string {
+	switch r.Status {
+	case StatusPass:
+		if r.Installed == "" {
+			return fmt.Sprintf("%s is absent as required", r.Name)
+		}
+		return fmt.Sprintf("%s version %s satisfies requirement %s", r.Name, r.Installed, r.Required)
+	case StatusFail:
+		if r.promoted {
+			return strings.Join(r.Warnings, "; ")
+		}
+		if len(r.Warnings) > 0 {
+			// Warnings of a result that failed anyway, e.g. an incompatibility, follow the failure.
+			return r.failureMessage() + "; " + strings.Join(r.Warnings, "; ")
+		}
+		return r.failureMessage()
+	default:
+		return fmt.Sprintf("failed to identify %s version: %s", r.Name, r.Error)
+	}
+}
+
+// failureMessage returns why a failed result did not satisfy its requirement.
+func (r Result) failureMessage() string {
+	switch {
+	case r.Error != "" && r.Installed == "":
+		return fmt.Sprintf("%s: %s", r.Name, r.Error)
+	case r.Error != "":
+		return fmt.Sprintf("%s version %s: %s", r.Name, r.Installed, r.Error)
+	case r.Locked != "" && r.Installed != r.Locked:
+		return fmt.Sprintf("%s version %s differs from locked version %s", r.Name, r.Installed, r.Locked)
+	case r.Reason == ReasonTooOld:
+		return fmt.Sprintf("%s version %s is older than required by %s%s", r.Name, r.Installed, r.Required, r.gapSuffix())
+	case r.Reason == ReasonTooNew:
+		return fmt.Sprintf("%s version %s is newer than allowed by %s%s", r.Name, r.Installed, r.Required, r.gapSuffix())
+	default:
+		return fmt.Sprintf("%s version %s does not satisfy requirement %s", r.Name, r.Installed, r.Required)
+	}
+}
+
+// gapSuffix returns the gap between the installed and required versions in parentheses, if known.
+func (r Result) gapSuffix() string {
+	if r.Gap == "" {
+		return ""
+	}
+	return " (" + r.Gap + ")"
+}
+
+// exitCode returns the exit code for a single result.
+func (r Result) exitCode() int {
+	switch r.Status {
+	case StatusPass:
+		return ExitSuccess
+	case StatusFail:
+		return ExitVersionMismatch
+	case StatusMissing:
+		return ExitToolMissing
+	default:
+		return ExitInternalError
+	}
+}
+
+// promoteWarnings fails every passing result that has warnings, for --warnings-as-errors.
+func promoteWarnings(results []Result) {
+	for i := range results {
+		result := &results[i]
+		if result.Status == StatusPass && len(result.Warnings) > 0 {
+			result.Satisfied = false
+			result.Status = StatusFail
+			result.promoted = true
+		}
+	}
+}
+
+// exitCodeForResults returns the most severe exit code of all results, so that e.g. a missing
+// tool is reported even if another tool has the wrong version.
+func exitCodeForResults(results []Result) int {
+	code := ExitSuccess
+	for _, result := range results {
+		if c := result.exitCode(); c > code {
+			code = c
+		}
+	}
+	return code
+}
@@ -17,12 +17,59 @@
 
 package cmd
 
+import "github.com/asimihsan/version-enforcer/config"
+
 var (
-	cfgFile string
-	verbose bool
+	cfgFile          string
+	cfgFormat        string
+	baselineFile     string
+	toolVersionsFile string
+	pinsFile         string
+	format           string
+	outputFile       string
+	summaryFormat    string
+	onlyFailures     bool
+	lockPath         string
+	locked           bool
+	watchConfig      bool
+	strictSemver     bool
+	minFoundDigits   int
+	verbose          bool
+	quiet            bool
+	explain          bool
+	skip             []string
+	retries          int
+	onFailure        string
+	warningsAsErrors bool
+	groupBy          string
 )
 
 func init() {
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (e.g. version-enforcer.hcl)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (e.g. version-enforcer.hcl), or - to read it from stdin")
+	rootCmd.PersistentFlags().StringVar(&cfgFormat, "config-format", "", "format of the config file (hcl, json, toml, or yaml), instead of detecting it from the extension; stdin defaults to hcl")
+	rootCmd.PersistentFlags().StringVar(&baselineFile, "baseline", "", "baseline config that the config may tighten but not loosen (e.g. baseline.hcl)")
+	rootCmd.PersistentFlags().StringVar(&toolVersionsFile, "tool-versions", "", "also enforce exact versions pinned in an asdf .tool-versions file")
+	rootCmd.PersistentFlags().StringVar(&pinsFile, "pins", "", "pins file of exact versions to enforce as name=version lines, overriding the config (e.g. versions.pins)")
+	rootCmd.PersistentFlags().StringVar(&format, "format", FormatText, "output format (text, json, junit, table, or csv)")
+	rootCmd.Flags().StringVar(&outputFile, "output-file", "", "write results to this file instead of stdout, e.g. for CI to upload, and a summary to stdout")
+	rootCmd.PersistentFlags().StringVar(&summaryFormat, "summary-format", "", "also write a summary line to stderr (text or json)")
+	rootCmd.PersistentFlags().BoolVar(&onlyFailures, "only-failures", false, "leave binaries that satisfy their requirements without warnings out of the results")
+	rootCmd.PersistentFlags().StringSliceVar(&skip, "skip", nil, "skip the binaries with these names, as well as those listed in $"+skipEnv+" (repeatable)")
+	rootCmd.PersistentFlags().StringVar(&groupBy, "group-by", "", "group results by status or severity, most severe first")
+	rootCmd.PersistentFlags().StringVar(&lockPath, "lock-path", config.DefaultLockPath, "lockfile written by the lock command")
+	rootCmd.PersistentFlags().BoolVar(&locked, "locked", false, "require the exact versions in the lockfile")
+	rootCmd.PersistentFlags().BoolVar(&strictSemver, "strict-semver", false, "fail if an installed version is not major.minor.patch semver")
+	rootCmd.PersistentFlags().IntVar(&minFoundDigits, "min-found-digits", 1, "fail if an installed version has fewer than this many components (1 to 3)")
+	rootCmd.Flags().BoolVar(&watchConfig, "watch", false, "re-run checks whenever the config file changes")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "only output failures and warnings")
+	rootCmd.PersistentFlags().IntVar(&retries, "retries", 0, "retry version commands that fail to start up to this many times, with exponential backoff")
+	rootCmd.Flags().StringVar(&onFailure, "on-failure", "", "run this shell command if any binary fails, with their names as arguments and in $"+onFailureEnv)
+	rootCmd.Flags().BoolVar(&warningsAsErrors, "warnings-as-errors", false, "fail binaries that pass with warnings, e.g. optional binaries that fail or binaries older than their latest release")
+	rootCmd.PersistentFlags().BoolVar(&explain, "explain", false, "explain how each requirement was parsed and why it passed or failed")
+
+	rootCmd.AddCommand(lockCmd)
+	rootCmd.AddCommand(initCmd)
+	rootCmd.AddCommand(importPreCommitCmd)
+	rootCmd.AddCommand(verifyCmd)
 }
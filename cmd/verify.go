@@ -0,0 +1,74 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"github.com/asimihsan/version-enforcer/config"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"io"
+	"os"
+)
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify --config <config file>",
+	Short: "Check that the config is valid without running any tools",
+	Long: `Check that the config is valid without running any tools.
+
+Every binary must name a supported program or say how else to find its version, have a
+requirement that can be parsed, and have a path that exists, and no binary may be configured
+twice with different requirements. Every problem is reported, rather than only the first. The
+exit code is 0 if the config is valid and 2 otherwise, which makes this a fast pre-commit hook.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		zlog := newLogger()
+
+		issues, err := verifyConfigFile(&zlog)
+		if err != nil {
+			zlog.Error().Err(err).Msg("failed to load config")
+			os.Exit(ExitConfigError)
+		}
+		os.Exit(writeIssues(os.Stdout, cfgFile, issues))
+	},
+}
+
+// verifyConfigFile verifies the --config file like loadConfigFile loads it, reading it from stdin if
+// the --config is stdinConfig.
+func verifyConfigFile(zlog *zerolog.Logger) ([]config.Issue, error) {
+	if cfgFile != stdinConfig {
+		return config.VerifyConfigFormat(cfgFile, cfgFormat, zlog)
+	}
+	src, err := io.ReadAll(stdin)
+	if err != nil {
+		return nil, err
+	}
+	return config.VerifyConfigBytes(src, cfgFormat, zlog)
+}
+
+// writeIssues writes a line per issue found in the config at path, or a success line if there are
+// none, and returns the exit code.
+func writeIssues(w io.Writer, path string, issues []config.Issue) int {
+	if len(issues) == 0 {
+		fprintSuccessLine(w, fmt.Sprintf("%s is valid", path))
+		return ExitSuccess
+	}
+	for _, issue := range issues {
+		fprintErrorLine(w, issue.Error())
+	}
+	return ExitConfigError
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"time"
+)
+
+// watchDebounce is how long to wait after the config changes before re-running checks, so that
+// editors that write a file in several steps only trigger a single run.
+const watchDebounce = 200 * time.Millisecond
+
+// watch runs the checks, then re-runs them whenever the config file changes, until interrupted. An
+// interrupt cancels the checks that are running, e.g. a probe that hangs, rather than waiting for
+// them to finish.
+func watch(ctx context.Context, zlog *zerolog.Logger) int {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		zlog.Error().Err(err).Msg("failed to create watcher")
+		return ExitInternalError
+	}
+	defer watcher.Close()
+
+	// Watch the directory rather than the file, because many editors save by replacing the file,
+	// which would silently end a watch on the file itself.
+	configPath := filepath.Clean(cfgFile)
+	if err := watcher.Add(filepath.Dir(configPath)); err != nil {
+		zlog.Error().Err(err).Str("path", configPath).Msg("failed to watch config")
+		return ExitConfigError
+	}
+
+	runEnforce(ctx, os.Stdout, os.Stderr, zlog)
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return ExitSuccess
+			}
+			if filepath.Clean(event.Name) != configPath || event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			debounce = time.After(watchDebounce)
+		case <-debounce:
+			debounce = nil
+			if !quiet {
+				fmt.Printf("\n%s changed, re-running checks\n", configPath)
+			}
+			runEnforce(ctx, os.Stdout, os.Stderr, zlog)
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return ExitSuccess
+			}
+			zlog.Error().Err(err).Msg("error watching config")
+		case <-ctx.Done():
+			return ExitSuccess
+		}
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package cmd
+
+import (
+	"context"
+	"github.com/asimihsan/version-enforcer/identifier"
+	"github.com/rs/zerolog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchInterruptCancelsChecks(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	defer func(c, f string, q bool) { cfgFile, format, quiet = c, f, q }(cfgFile, format, quiet)
+	cfgFile = filepath.Join(t.TempDir(), "version-enforcer.hcl")
+	format, quiet = FormatJSON, true
+	if err := os.WriteFile(cfgFile, []byte("binary \"go\" {\n  version = \"~1.21\"\n}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// The identification hangs, like a probe that never exits, until its context is cancelled.
+	started := make(chan struct{})
+	defer func(original func(context.Context, identifier.Program, identifier.IdentifyOptions, *zerolog.Logger) (identifier.Identification, error)) {
+		identifyWithOptions = original
+	}(identifyWithOptions)
+	identifyWithOptions = func(ctx context.Context, p identifier.Program, opts identifier.IdentifyOptions, zlog *zerolog.Logger) (identifier.Identification, error) {
+		close(started)
+		<-ctx.Done()
+		return identifier.Identification{}, ctx.Err()
+	}
+
+	done := make(chan int)
+	go func() { done <- watch(context.Background(), &zlog) }()
+	<-started
+
+	process, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := process.Signal(os.Interrupt); err != nil {
+		t.Skipf("cannot interrupt the test process: %v", err)
+	}
+	select {
+	case code := <-done:
+		if code != ExitSuccess {
+			t.Errorf("watch() = %d, want %d", code, ExitSuccess)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch did not return after an interrupt while a check was running")
+	}
+}
@@ -17,11 +17,107 @@
 
 package command
 
-import "os/exec"
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// Output is what a command wrote to each of its output streams.
+type Output struct {
+	Stdout string
+	Stderr string
+}
+
+// dirKey is the context key for the directory that commands are run in.
+type dirKey struct{}
+
+// WithDir returns a copy of ctx in which commands run by this package are run in dir, e.g. so that
+// a version manager reads the config of a project there. An empty dir means the current directory.
+func WithDir(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, dirKey{}, dir)
+}
+
+// dirFromContext returns the directory set with WithDir, or an empty string.
+func dirFromContext(ctx context.Context) string {
+	dir, _ := ctx.Value(dirKey{}).(string)
+	return dir
+}
 
 // RunCommand runs the command and returns the output and error.
 func RunCommand(name string, arg ...string) (string, error) {
-	cmd := exec.Command(name, arg...)
+	return RunCommandContext(context.Background(), name, arg...)
+}
+
+// RunCommandContext is like RunCommand, but kills the command if ctx is done before it exits.
+func RunCommandContext(ctx context.Context, name string, arg ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, name, arg...)
+	cmd.Dir = dirFromContext(ctx)
 	output, err := cmd.CombinedOutput()
 	return string(output), err
 }
+
+// RunCommandOutput is like RunCommandContext, but keeps stdout and stderr separate.
+func RunCommandOutput(ctx context.Context, name string, arg ...string) (Output, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, arg...)
+	cmd.Dir = dirFromContext(ctx)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return Output{Stdout: stdout.String(), Stderr: stderr.String()}, err
+}
+
+// RunCommandWithEnv is like RunCommandOutput, but adds env, as "key=value" strings, to the
+// environment that the command inherits.
+func RunCommandWithEnv(ctx context.Context, env []string, name string, arg ...string) (Output, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, name, arg...)
+	cmd.Dir = dirFromContext(ctx)
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	return Output{Stdout: stdout.String(), Stderr: stderr.String()}, err
+}
+
+// RunFunc runs a command and returns what it wrote to stdout and stderr, like RunCommandOutput.
+type RunFunc func(ctx context.Context, name string, arg ...string) (Output, error)
+
+// Retry returns a RunFunc that runs commands with run, and if a command could not be started runs it
+// again up to retries times, waiting backoff before the first retry and twice as long before each
+// one after that. Commands that ran, even if they exited with an error, and commands that do not
+// exist are not retried.
+func Retry(run RunFunc, retries int, backoff time.Duration) RunFunc {
+	return func(ctx context.Context, name string, arg ...string) (Output, error) {
+		output, err := run(ctx, name, arg...)
+		for attempt := 0; attempt < retries && isLaunchError(ctx, err); attempt++ {
+			timer := time.NewTimer(backoff << attempt)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return output, err
+			case <-timer.C:
+			}
+			output, err = run(ctx, name, arg...)
+		}
+		return output, err
+	}
+}
+
+// isLaunchError returns whether err means that a command could not be started, e.g. because a
+// network filesystem was briefly unavailable, rather than that it ran and failed or is missing.
+func isLaunchError(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return false
+	}
+	return !errors.Is(err, exec.ErrNotFound) && !errors.Is(err, fs.ErrNotExist)
+}
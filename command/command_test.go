@@ -0,0 +1,133 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package command
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRunCommandContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	if _, err := RunCommandContext(ctx, "sleep", "10"); err == nil {
+		t.Fatalf("RunCommandContext returned no error for a canceled command")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("RunCommandContext took %s, want the command to be killed when canceled", elapsed)
+	}
+}
+
+func TestRunCommandOutputSeparatesStreams(t *testing.T) {
+	output, err := RunCommandOutput(context.Background(), "sh", "-c", "echo out; echo err >&2")
+	if err != nil {
+		t.Fatalf("RunCommandOutput returned error: %v", err)
+	}
+	if output.Stdout != "out\n" {
+		t.Errorf("RunCommandOutput().Stdout = %q, want %q", output.Stdout, "out\n")
+	}
+	if output.Stderr != "err\n" {
+		t.Errorf("RunCommandOutput().Stderr = %q, want %q", output.Stderr, "err\n")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	calls := 0
+	run := func(ctx context.Context, name string, arg ...string) (Output, error) {
+		calls++
+		if calls <= 2 {
+			return Output{}, &fs.PathError{Op: "fork/exec", Path: name, Err: syscall.EIO}
+		}
+		return Output{Stdout: "go version go1.21.3 linux/amd64\n"}, nil
+	}
+
+	output, err := Retry(run, 3, time.Millisecond)(context.Background(), "go", "version")
+	if err != nil {
+		t.Fatalf("Retry() returned error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Retry() ran the command %d times, want %d", calls, 3)
+	}
+	if output.Stdout != "go version go1.21.3 linux/amd64\n" {
+		t.Errorf("Retry().Stdout = %q, want the output of the successful run", output.Stdout)
+	}
+}
+
+func TestRetryGivesUp(t *testing.T) {
+	launchErr := &fs.PathError{Op: "fork/exec", Path: "go", Err: syscall.EIO}
+	exitErr := exec.Command("sh", "-c", "exit 1").Run()
+
+	tests := []struct {
+		name      string
+		err       error
+		retries   int
+		wantCalls int
+	}{
+		{"no retries by default", launchErr, 0, 1},
+		{"launch errors up to the limit", launchErr, 2, 3},
+		{"command exited with an error", exitErr, 2, 1},
+		{"command not found", exec.ErrNotFound, 2, 1},
+	}
+	for _, tt := range tests {
+		calls := 0
+		run := func(ctx context.Context, name string, arg ...string) (Output, error) {
+			calls++
+			return Output{}, tt.err
+		}
+		_, err := Retry(run, tt.retries, time.Millisecond)(context.Background(), "go", "version")
+		if !errors.Is(err, tt.err) {
+			t.Errorf("%s: Retry() error = %v, want %v", tt.name, err, tt.err)
+		}
+		if calls != tt.wantCalls {
+			t.Errorf("%s: Retry() ran the command %d times, want %d", tt.name, calls, tt.wantCalls)
+		}
+	}
+}
+
+func TestRunCommandWithEnv(t *testing.T) {
+	output, err := RunCommandWithEnv(context.Background(), []string{"GREETING=hello"}, "sh", "-c", "echo $GREETING")
+	if err != nil {
+		t.Fatalf("RunCommandWithEnv returned error: %v", err)
+	}
+	if output.Stdout != "hello\n" {
+		t.Errorf("RunCommandWithEnv().Stdout = %q, want %q", output.Stdout, "hello\n")
+	}
+}
+
+func TestRunCommandOutputWithDir(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := RunCommandOutput(WithDir(context.Background(), dir), "pwd")
+	if err != nil {
+		t.Fatalf("RunCommandOutput returned error: %v", err)
+	}
+	if got := strings.TrimSpace(output.Stdout); got != dir {
+		t.Errorf("RunCommandOutput(pwd) = %q, want %q", got, dir)
+	}
+}
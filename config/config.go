@@ -18,49 +18,579 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"github.com/asimihsan/version-enforcer/identifier"
 	"github.com/hashicorp/hcl/v2"
-	"github.com/hashicorp/hcl/v2/hclsimple"
 	"github.com/rs/zerolog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// VersionSourceGoVersionM identifies a binary built by `go install` from the main module version
+// embedded in it, as printed by `go version -m`. The binary does not need to be a supported program.
+const VersionSourceGoVersionM = "go-version-m"
+
+// SupportedSchemaVersion is the newest config schema_version that this version of the enforcer
+// understands. Configs that omit schema_version are treated as the oldest schema.
+const SupportedSchemaVersion = 1
+
+// What to do when a binary whose name is a glob matches no executables in $PATH.
+const (
+	OnNoMatchError = "error"
+	OnNoMatchSkip  = "skip"
+)
+
+// What to do when a binary is older than the latest release fetched from its latest_source.
+const (
+	OnOutdatedWarn  = "warn"
+	OnOutdatedError = "error"
+)
+
+var (
+	ErrLooserThanBaseline   = errors.New("requirement is looser than baseline")
+	ErrUnknownVersionSource = errors.New("unknown version source")
+	ErrEmptyVersionArgs     = errors.New("version_args must not be empty")
+	ErrMissingVersion       = errors.New("version, versions, or min_version and max_version, must be set")
+	ErrConflictingVersion   = errors.New("only one of version, versions, or min_version and max_version can be set")
+	ErrSchemaTooNew         = errors.New("config schema_version is newer than this enforcer supports")
+	ErrGlobRequiresProgram  = errors.New("a binary whose name is a glob must set program")
+	ErrUnknownOnNoMatch     = errors.New("unknown on_no_match")
+	ErrAbsentWithVersion    = errors.New("a binary that must be absent cannot have a version requirement")
+	ErrUnknownOnOutdated    = errors.New("unknown on_outdated")
+	ErrInvalidLatestSource  = errors.New("latest_source must be an http or https URL")
 )
 
 type Config struct {
-	Binary []*Binary `hcl:"binary,block"`
+	SchemaVersion int       `hcl:"schema_version,optional" yaml:"schema_version" toml:"schema_version"`
+	AssertEqual   []string  `hcl:"assert_equal,optional" yaml:"assert_equal" toml:"assert_equal"`
+	Binary        []*Binary `hcl:"binary,block" yaml:"binary" toml:"binary"`
 }
 
 type Binary struct {
-	Name    string `hcl:"name,label"`
-	Version string `hcl:"version"`
+	Name          string   `hcl:"name,label" yaml:"name" toml:"name"`
+	Version       string   `hcl:"version,optional" yaml:"version" toml:"version"`
+	Versions      []string `hcl:"versions,optional" yaml:"versions" toml:"versions"`
+	MinVersion    string   `hcl:"min_version,optional" yaml:"min_version" toml:"min_version"`
+	MaxVersion    string   `hcl:"max_version,optional" yaml:"max_version" toml:"max_version"`
+	InstallHint   string   `hcl:"install_hint,optional" yaml:"install_hint" toml:"install_hint"`
+	VersionSource string   `hcl:"version_source,optional" yaml:"version_source" toml:"version_source"`
+	VersionArgs   []string `hcl:"version_args,optional" yaml:"version_args" toml:"version_args"`
+	Exclude       []string `hcl:"exclude,optional" yaml:"exclude" toml:"exclude"`
+	Path          string   `hcl:"path,optional" yaml:"path" toml:"path"`
+	PathPrefix    string   `hcl:"path_prefix,optional" yaml:"path_prefix" toml:"path_prefix"`
+	Commit        string   `hcl:"commit,optional" yaml:"commit" toml:"commit"`
+	Probe         string   `hcl:"probe,optional" yaml:"probe" toml:"probe"`
+	VersionEnv    string   `hcl:"version_env,optional" yaml:"version_env" toml:"version_env"`
+	Comparator    string   `hcl:"comparator,optional" yaml:"comparator" toml:"comparator"`
+	Program       string   `hcl:"program,optional" yaml:"program" toml:"program"`
+	OnNoMatch     string   `hcl:"on_no_match,optional" yaml:"on_no_match" toml:"on_no_match"`
+	Invoker       []string `hcl:"invoker,optional" yaml:"invoker" toml:"invoker"`
+	Absent        bool     `hcl:"absent,optional" yaml:"absent" toml:"absent"`
+	WorkingDir    string   `hcl:"working_dir,optional" yaml:"working_dir" toml:"working_dir"`
+	LatestSource  string   `hcl:"latest_source,optional" yaml:"latest_source" toml:"latest_source"`
+	OnOutdated    string   `hcl:"on_outdated,optional" yaml:"on_outdated" toml:"on_outdated"`
+	Optional      bool     `hcl:"optional,optional" yaml:"optional" toml:"optional"`
+}
+
+// IsGlob returns true if the binary's name is a glob, such as "python3.*", that matches the names
+// of executables in $PATH.
+func (b *Binary) IsGlob() bool {
+	return strings.ContainsAny(b.Name, "*?[")
+}
+
+// ProgramName returns the name of the supported program that the binary is identified as, which
+// is its program if set and otherwise its name.
+func (b *Binary) ProgramName() string {
+	if b.Program != "" {
+		return b.Program
+	}
+	return b.Name
+}
+
+// Requirement returns the binary's version requirement. min_version and max_version allow versions
+// from min_version, inclusive, up to max_version, exclusive, and either may be omitted. More than
+// one requirement in versions cannot be returned as a single requirement.
+func (b *Binary) Requirement() (*identifier.Requirement, error) {
+	switch {
+	case len(b.Versions) > 0 && (b.Version != "" || b.MinVersion != "" || b.MaxVersion != ""):
+		return nil, ErrConflictingVersion
+	case len(b.Versions) == 1:
+		return identifier.NewRequirement(b.Versions[0])
+	case len(b.Versions) > 1:
+		return nil, fmt.Errorf("%w: more than one requirement in versions", identifier.ErrUnsupportedRequirement)
+	case b.MinVersion == "" && b.MaxVersion == "":
+		if b.Version == "" {
+			return nil, ErrMissingVersion
+		}
+		return identifier.NewRequirement(b.Version)
+	case b.Version != "":
+		return nil, ErrConflictingVersion
+	case b.MaxVersion == "":
+		return identifier.NewRequirement(">=" + b.MinVersion)
+	case b.MinVersion == "":
+		return identifier.NewRequirement("<" + b.MaxVersion)
+	default:
+		return identifier.NewRangeRequirement(b.MinVersion, b.MaxVersion)
+	}
+}
+
+// versionRequirements returns the requirements in versions, all of which must be satisfied, or
+// version on its own.
+func (b *Binary) versionRequirements() []string {
+	if len(b.Versions) > 0 {
+		return b.Versions
+	}
+	if b.Version != "" {
+		return []string{b.Version}
+	}
+	return nil
+}
+
+// VersionComparator returns the comparator named by the binary's comparator field, falling back to
+// the comparator of its program.
+func (b *Binary) VersionComparator() (identifier.Comparator, error) {
+	if b.Comparator != "" {
+		return identifier.ComparatorByName(b.Comparator)
+	}
+	if program, err := identifier.GetProgram(b.ProgramName()); err == nil {
+		return identifier.GetComparator(*program), nil
+	}
+	return identifier.SemverComparator{}, nil
+}
+
+// Satisfies returns true if version satisfies the binary's requirement, compared with its
+// comparator.
+func (b *Binary) Satisfies(version string) (bool, error) {
+	comparator, err := b.VersionComparator()
+	if err != nil {
+		return false, err
+	}
+	if b.MinVersion == "" && b.MaxVersion == "" {
+		requirements := b.versionRequirements()
+		if len(requirements) == 0 {
+			return false, ErrMissingVersion
+		}
+		for _, requirement := range requirements {
+			satisfied, err := identifier.SatisfiesWith(version, requirement, comparator)
+			if err != nil || !satisfied {
+				return false, err
+			}
+		}
+		return true, nil
+	}
+	if _, ok := comparator.(identifier.SemverComparator); !ok {
+		return false, fmt.Errorf("%w: min_version and max_version", identifier.ErrUnsupportedRequirement)
+	}
+	requirement, err := b.Requirement()
+	if err != nil {
+		return false, err
+	}
+	return identifier.SatisfiesRequirement(version, *requirement)
+}
+
+// Explain describes how the binary's requirement was parsed and whether version satisfies it, for
+// --explain.
+func (b *Binary) Explain(version string) (string, error) {
+	comparator, err := b.VersionComparator()
+	if err != nil {
+		return "", err
+	}
+	if b.MinVersion == "" && b.MaxVersion == "" {
+		requirements := b.versionRequirements()
+		if len(requirements) == 0 {
+			return "", ErrMissingVersion
+		}
+		explanations := make([]string, 0, len(requirements))
+		for _, requirement := range requirements {
+			explanation, err := identifier.ExplainWith(version, requirement, comparator)
+			if err != nil {
+				return "", err
+			}
+			explanations = append(explanations, explanation)
+		}
+		return strings.Join(explanations, "\n"), nil
+	}
+	requirement, err := b.Requirement()
+	if err != nil {
+		return "", err
+	}
+	return identifier.ExplainRequirement(version, *requirement), nil
+}
+
+// CompareToRequirement returns -1 if version is older than every version that the binary's
+// requirement allows, 1 if it is newer than every version the requirement allows, and 0 otherwise.
+// With more than one requirement in versions, the first that version is outside of decides.
+func (b *Binary) CompareToRequirement(version string) (int, error) {
+	comparator, err := b.VersionComparator()
+	if err != nil {
+		return 0, err
+	}
+	if b.MinVersion == "" && b.MaxVersion == "" {
+		requirements := b.versionRequirements()
+		if len(requirements) == 0 {
+			return 0, ErrMissingVersion
+		}
+		for _, requirement := range requirements {
+			c, err := identifier.CompareToRequirementWith(version, requirement, comparator)
+			if err != nil || c != 0 {
+				return c, err
+			}
+		}
+		return 0, nil
+	}
+	requirement, err := b.Requirement()
+	if err != nil {
+		return 0, err
+	}
+	v, err := identifier.ParseVersion(version)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q: %v", identifier.ErrInvalidVersion, version, err)
+	}
+	return identifier.CompareToRequirement(*v, *requirement), nil
+}
+
+// checkRequirement returns an error if the binary's requirement cannot be used with its comparator.
+func (b *Binary) checkRequirement() error {
+	comparator, err := b.VersionComparator()
+	if err != nil {
+		return err
+	}
+	if len(b.Versions) > 0 && (b.Version != "" || b.MinVersion != "" || b.MaxVersion != "") {
+		return ErrConflictingVersion
+	}
+	if b.MinVersion != "" || b.MaxVersion != "" {
+		if _, ok := comparator.(identifier.SemverComparator); !ok {
+			return fmt.Errorf("%w: min_version and max_version", identifier.ErrUnsupportedRequirement)
+		}
+		_, err := b.Requirement()
+		return err
+	}
+	requirements := b.versionRequirements()
+	if len(requirements) == 0 {
+		return ErrMissingVersion
+	}
+	for _, requirement := range requirements {
+		if err := identifier.CheckRequirement(requirement, comparator); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RequirementString returns the binary's version requirement as it is shown to users, e.g.
+// "^1.2.3" or ">=1.2 <2.0". It can be used as a version requirement itself, so the requirements in
+// versions are combined into one with identifier.AllOf.
+func (b *Binary) RequirementString() string {
+	if b.Absent {
+		return "absent"
+	}
+	parts := append([]string(nil), b.versionRequirements()...)
+	if b.MinVersion != "" {
+		parts = append(parts, ">="+b.MinVersion)
+	}
+	if b.MaxVersion != "" {
+		parts = append(parts, "<"+b.MaxVersion)
+	}
+	return identifier.AllOf(parts...)
 }
 
+// LoadConfig loads the config at configPath and checks it with Validate, returning the first issue
+// found as the error. The format of the config is detected from its extension.
 func LoadConfig(configPath string, zlog *zerolog.Logger) (*Config, error) {
+	return LoadConfigFormat(configPath, FormatAuto, zlog)
+}
+
+// LoadConfigFormat is like LoadConfig, but decodes the config in format, e.g. FormatYAML, whatever
+// its extension.
+func LoadConfigFormat(configPath string, format string, zlog *zerolog.Logger) (*Config, error) {
+	cfg, issues, err := loadConfig(configPath, format, zlog)
+	return firstIssue(cfg, issues, err, zlog)
+}
+
+// LoadConfigBytes is like LoadConfigFormat, but decodes src, a config that has already been read,
+// e.g. from stdin. The format defaults to HCL, and relative paths in the config are relative to the
+// current directory.
+func LoadConfigBytes(src []byte, format string, zlog *zerolog.Logger) (*Config, error) {
+	cfg, issues, err := loadConfigBytes(src, bytesConfigName, ".", bytesFormat(format), zlog)
+	return firstIssue(cfg, issues, err, zlog)
+}
+
+// firstIssue logs every issue found in a config by loadConfig, returning the first as the error.
+func firstIssue(cfg *Config, issues []Issue, err error, zlog *zerolog.Logger) (*Config, error) {
+	if err != nil {
+		return nil, err
+	}
+	for _, issue := range issues {
+		zlog.Error().Err(issue.Err).Str("binary", issue.Binary).Msg("invalid binary")
+	}
+	if len(issues) > 0 {
+		return nil, issues[0]
+	}
+	return cfg, nil
+}
+
+// VerifyConfig loads the config at configPath like LoadConfig, but returns every issue found in it
+// rather than stopping at the first. It also checks that no binary is configured twice with
+// different requirements and that the paths of binaries exist. The error is only set if the config
+// cannot be read at all.
+func VerifyConfig(configPath string, zlog *zerolog.Logger) ([]Issue, error) {
+	return VerifyConfigFormat(configPath, FormatAuto, zlog)
+}
+
+// VerifyConfigFormat is like VerifyConfig, but decodes the config in format whatever its extension.
+func VerifyConfigFormat(configPath string, format string, zlog *zerolog.Logger) ([]Issue, error) {
+	cfg, issues, err := loadConfig(configPath, format, zlog)
+	if err != nil {
+		return nil, err
+	}
+	issues = append(issues, checkConflicts(cfg)...)
+	return append(issues, checkPaths(cfg)...), nil
+}
+
+// VerifyConfigBytes is like VerifyConfigFormat, but checks src, a config that has already been
+// read, as LoadConfigBytes does.
+func VerifyConfigBytes(src []byte, format string, zlog *zerolog.Logger) ([]Issue, error) {
+	cfg, issues, err := loadConfigBytes(src, bytesConfigName, ".", bytesFormat(format), zlog)
+	if err != nil {
+		return nil, err
+	}
+	issues = append(issues, checkConflicts(cfg)...)
+	return append(issues, checkPaths(cfg)...), nil
+}
+
+// bytesConfigName is the name of a config loaded from bytes in errors.
+const bytesConfigName = "config"
+
+// bytesFormat returns format, or FormatHCL if it is FormatAuto, as there is no extension to detect
+// the format of a config loaded from bytes.
+func bytesFormat(format string) string {
+	if format == FormatAuto {
+		return FormatHCL
+	}
+	return format
+}
+
+// loadConfig reads the config at configPath and loads it with loadConfigBytes, in format or the
+// format given by its extension, relative to its directory.
+func loadConfig(configPath string, format string, zlog *zerolog.Logger) (*Config, []Issue, error) {
+	format, err := configFormat(configPath, format)
+	var src []byte
+	if err == nil {
+		src, err = os.ReadFile(configPath)
+	}
+	if err != nil {
+		zlog.Error().Stack().Err(err).Msg("Failed to decode config")
+		return nil, nil, err
+	}
+	return loadConfigBytes(src, configPath, filepath.Dir(configPath), format, zlog)
+}
+
+// loadConfigBytes decodes src, a config named name in format, resolves the go.mod versions and
+// probe paths of its binaries relative to dir, and validates the binaries that could be resolved.
+func loadConfigBytes(src []byte, name string, dir string, format string, zlog *zerolog.Logger) (*Config, []Issue, error) {
+	if _, err := configFormat(name, format); err != nil {
+		zlog.Error().Err(err).Msg("Failed to decode config")
+		return nil, nil, err
+	}
 	var cfg Config
-	err := hclsimple.DecodeFile(configPath, nil, &cfg)
+	err := decodeConfig(src, name, format, &cfg)
 	if err != nil {
+		// Diagnostics are logged rather than printed, so that stdout only has results.
 		if diagnostics, ok := err.(hcl.Diagnostics); ok {
 			for _, diagnostic := range diagnostics {
-				fmt.Println(diagnostic)
+				zlog.Error().Err(diagnostic).Msg("Failed to decode config")
 			}
 		} else {
 			zlog.Error().Stack().Err(err).Msg("Failed to decode config")
 		}
-		return nil, err
+		return nil, nil, err
+	}
+
+	if cfg.SchemaVersion > SupportedSchemaVersion {
+		err := fmt.Errorf("%w: %s has schema_version %d, but the newest supported is %d; upgrade version-enforcer to use this config",
+			ErrSchemaTooNew, name, cfg.SchemaVersion, SupportedSchemaVersion)
+		zlog.Error().Err(err).Msg("unsupported config schema")
+		return nil, nil, err
 	}
 
+	var issues []Issue
+	resolved := &Config{SchemaVersion: cfg.SchemaVersion, AssertEqual: cfg.AssertEqual}
 	for _, binary := range cfg.Binary {
-		_, err := identifier.GetProgram(binary.Name)
+		if err := resolveBinary(dir, binary); err != nil {
+			issues = append(issues, Issue{Binary: binary.Name, Err: err})
+			continue
+		}
+		resolved.Binary = append(resolved.Binary, binary)
+	}
+	issues = append(issues, Validate(resolved)...)
+
+	return &cfg, issues, nil
+}
+
+// resolveBinary replaces a go.mod version with the version in the go.mod file next to the config,
+// and resolves the path of a probe script and the working directory relative to the config's
+// directory, dir.
+func resolveBinary(dir string, binary *Binary) error {
+	var err error
+	if binary.Version == GoModVersion {
+		if binary.Name != "go" {
+			return ErrGoModVersionUsage
+		}
+		binary.Version, err = resolveGoModVersion(dir)
+		if err != nil {
+			return err
+		}
+	}
+	if binary.WorkingDir != "" && !filepath.IsAbs(binary.WorkingDir) {
+		binary.WorkingDir = filepath.Join(dir, binary.WorkingDir)
+	}
+	if binary.Probe != "" && binary.VersionEnv == "" {
+		binary.Probe, err = resolveProbe(dir, binary.Probe)
 		if err != nil {
-			zlog.Error().Err(err).Interface("binary", binary).Msg("failed to get program")
-			return nil, err
+			return err
+		}
+	}
+	return nil
+}
+
+// AddMissing appends the binaries of other that c does not already configure.
+func (c *Config) AddMissing(other *Config) {
+	configured := make(map[string]bool)
+	for _, binary := range c.Binary {
+		configured[binary.Name] = true
+	}
+	for _, binary := range other.Binary {
+		if !configured[binary.Name] {
+			c.Binary = append(c.Binary, binary)
+			configured[binary.Name] = true
+		}
+	}
+}
+
+// MergeBaseline returns a config containing the binaries of both baseline and local. If both
+// configure the same binary then the local binary is used, but its requirement must be at least as
+// strict as the baseline requirement, i.e. it may tighten the baseline but never loosen it. The
+// local binary also keeps the versions that the baseline excludes, and may not otherwise loosen it
+// by being optional, skipping when no executable matches, or finding or comparing its version
+// differently.
+func MergeBaseline(baseline *Config, local *Config) (*Config, error) {
+	localBinaries := make(map[string][]*Binary)
+	for _, binary := range local.Binary {
+		localBinaries[binary.Name] = append(localBinaries[binary.Name], binary)
+	}
+
+	// Every local binary is checked against every baseline binary with the same name, so that a
+	// binary configured twice cannot loosen the baseline either.
+	tightened := make(map[*Binary]*Binary)
+	for _, baselineBinary := range baseline.Binary {
+		for _, localBinary := range localBinaries[baselineBinary.Name] {
+			if err := checkNotLooser(baselineBinary, localBinary); err != nil {
+				return nil, err
+			}
+			binary, ok := tightened[localBinary]
+			if !ok {
+				copied := *localBinary
+				binary = &copied
+				tightened[localBinary] = binary
+			}
+			binary.Exclude = appendMissing(binary.Exclude, baselineBinary.Exclude)
+		}
+	}
+
+	var merged Config
+	seen := make(map[string]bool)
+	for _, baselineBinary := range baseline.Binary {
+		overrides, ok := localBinaries[baselineBinary.Name]
+		if !ok {
+			merged.Binary = append(merged.Binary, baselineBinary)
+			continue
+		}
+		if seen[baselineBinary.Name] {
+			continue
+		}
+		for _, localBinary := range overrides {
+			merged.Binary = append(merged.Binary, tightened[localBinary])
+		}
+		seen[baselineBinary.Name] = true
+	}
+
+	for _, binary := range local.Binary {
+		if !seen[binary.Name] {
+			merged.Binary = append(merged.Binary, binary)
 		}
+	}
+
+	merged.AssertEqual = baseline.AssertEqual
+	if len(local.AssertEqual) > 0 {
+		merged.AssertEqual = local.AssertEqual
+	}
 
-		_, err = identifier.NewRequirement(binary.Version)
+	return &merged, nil
+}
+
+// checkNotLooser returns ErrLooserThanBaseline if local, which configures the same binary as
+// baseline, loosens it.
+func checkNotLooser(baseline *Binary, local *Binary) error {
+	// A binary that must be absent has no requirement to compare, so it can only be replaced by
+	// another binary that must be absent.
+	if baseline.Absent || local.Absent {
+		if baseline.Absent != local.Absent {
+			return fmt.Errorf("%w: %s requirement %q must be at least as strict as baseline %q",
+				ErrLooserThanBaseline, local.Name, local.RequirementString(), baseline.RequirementString())
+		}
+	} else {
+		// The requirements are compared as they are shown to users, which combines every clause of
+		// version, versions, or min_version and max_version.
+		strictness, err := identifier.RequirementStringStrictness(local.RequirementString(), baseline.RequirementString())
 		if err != nil {
-			zlog.Error().Err(err).Interface("binary", binary).Msg("failed to parse requirement")
-			return nil, err
+			return err
+		}
+		if strictness != identifier.StrictnessEqual && strictness != identifier.StrictnessStricter {
+			return fmt.Errorf("%w: %s requirement %q must be at least as strict as baseline %q",
+				ErrLooserThanBaseline, local.Name, local.RequirementString(), baseline.RequirementString())
+		}
+	}
+
+	if local.Optional && !baseline.Optional {
+		return fmt.Errorf("%w: %s must not be optional, as the baseline requires it", ErrLooserThanBaseline, local.Name)
+	}
+	if local.OnNoMatch == OnNoMatchSkip && baseline.OnNoMatch != OnNoMatchSkip {
+		return fmt.Errorf("%w: %s must not set on_no_match = %q, as the baseline does not", ErrLooserThanBaseline, local.Name, OnNoMatchSkip)
+	}
+
+	// A different comparator, probe, or environment variable would check a different version.
+	for _, field := range []struct {
+		name            string
+		local, baseline string
+	}{
+		{"comparator", local.Comparator, baseline.Comparator},
+		{"probe", local.Probe, baseline.Probe},
+		{"version_env", local.VersionEnv, baseline.VersionEnv},
+	} {
+		if field.local != field.baseline {
+			return fmt.Errorf("%w: %s %s %q must match baseline %q", ErrLooserThanBaseline, local.Name, field.name, field.local, field.baseline)
 		}
 	}
+	return nil
+}
 
-	return &cfg, nil
+// appendMissing appends the values of other that are not already in values, without modifying
+// values.
+func appendMissing(values []string, other []string) []string {
+	result := append([]string(nil), values...)
+	for _, value := range other {
+		found := false
+		for _, existing := range result {
+			if existing == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			result = append(result, value)
+		}
+	}
+	return result
 }
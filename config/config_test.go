@@ -0,0 +1,497 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"github.com/asimihsan/version-enforcer/identifier"
+	"github.com/rs/zerolog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMergeBaseline(t *testing.T) {
+	baseline := &Config{Binary: []*Binary{
+		{Name: "go", Version: ">= 1.19"},
+		{Name: "git", Version: "~2"},
+	}}
+
+	local := &Config{Binary: []*Binary{
+		{Name: "go", Version: "~1.21"},
+		{Name: "make", Version: "^4.2.1"},
+	}}
+	merged, err := MergeBaseline(baseline, local)
+	if err != nil {
+		t.Fatalf("MergeBaseline returned error: %v", err)
+	}
+	expected := map[string]string{"go": "~1.21", "git": "~2", "make": "^4.2.1"}
+	if len(merged.Binary) != len(expected) {
+		t.Fatalf("MergeBaseline returned %d binaries, want %d", len(merged.Binary), len(expected))
+	}
+	for _, binary := range merged.Binary {
+		if expected[binary.Name] != binary.Version {
+			t.Errorf("merged %s version = %s, want %s", binary.Name, binary.Version, expected[binary.Name])
+		}
+	}
+
+	looser := &Config{Binary: []*Binary{
+		{Name: "go", Version: ">= 1.17"},
+	}}
+	_, err = MergeBaseline(baseline, looser)
+	if !errors.Is(err, ErrLooserThanBaseline) {
+		t.Errorf("MergeBaseline error = %v, want %v", err, ErrLooserThanBaseline)
+	}
+}
+
+func TestMergeBaselineClauses(t *testing.T) {
+	baseline := &Config{Binary: []*Binary{
+		{Name: "go", Version: ">=1.21.0 <1.23.0"},
+		{Name: "git", MinVersion: "2.30", MaxVersion: "3"},
+	}}
+
+	local := &Config{Binary: []*Binary{
+		{Name: "go", Version: ">=1.21.0 <1.22.0"},
+		{Name: "git", Version: "~2.40"},
+	}}
+	if _, err := MergeBaseline(baseline, local); err != nil {
+		t.Errorf("MergeBaseline returned error: %v", err)
+	}
+
+	looser := &Config{Binary: []*Binary{
+		{Name: "go", Version: ">=1.20.0 <1.22.0"},
+	}}
+	if _, err := MergeBaseline(baseline, looser); !errors.Is(err, ErrLooserThanBaseline) {
+		t.Errorf("MergeBaseline error = %v, want %v", err, ErrLooserThanBaseline)
+	}
+}
+
+func TestMergeBaselineAlternatives(t *testing.T) {
+	baseline := &Config{Binary: []*Binary{
+		{Name: "go", Version: "=1.20.3 || =1.21.5"},
+	}}
+
+	local := &Config{Binary: []*Binary{
+		{Name: "go", Version: "=1.21.5"},
+	}}
+	if _, err := MergeBaseline(baseline, local); err != nil {
+		t.Errorf("MergeBaseline returned error: %v", err)
+	}
+	if _, err := MergeBaseline(&Config{Binary: []*Binary{{Name: "go", Version: ">= 1.20"}}}, &Config{Binary: baseline.Binary}); err != nil {
+		t.Errorf("MergeBaseline with alternatives within the baseline returned error: %v", err)
+	}
+
+	looser := &Config{Binary: []*Binary{
+		{Name: "go", Version: "=1.21.5 || =1.22.0"},
+	}}
+	if _, err := MergeBaseline(baseline, looser); !errors.Is(err, ErrLooserThanBaseline) {
+		t.Errorf("MergeBaseline error = %v, want %v", err, ErrLooserThanBaseline)
+	}
+}
+
+func TestMergeBaselineVersions(t *testing.T) {
+	baseline := &Config{Binary: []*Binary{
+		{Name: "go", Versions: []string{">= 1.19", "< 1.23"}},
+	}}
+
+	for _, versions := range [][]string{{">= 1.21", "< 1.22"}, {"~1.21 || ~1.22", ">= 1.21.1"}} {
+		local := &Config{Binary: []*Binary{{Name: "go", Versions: versions}}}
+		if _, err := MergeBaseline(baseline, local); err != nil {
+			t.Errorf("MergeBaseline(versions %q) returned error: %v", versions, err)
+		}
+	}
+
+	looser := &Config{Binary: []*Binary{
+		{Name: "go", Versions: []string{">= 1.17", "< 1.22"}},
+	}}
+	if _, err := MergeBaseline(baseline, looser); !errors.Is(err, ErrLooserThanBaseline) {
+		t.Errorf("MergeBaseline error = %v, want %v", err, ErrLooserThanBaseline)
+	}
+}
+
+func TestMergeBaselineFields(t *testing.T) {
+	baseline := &Config{Binary: []*Binary{
+		{Name: "go", Version: "~1.21", Exclude: []string{"1.21.1"}},
+		{Name: "protoc", Version: "~25", Comparator: "semver"},
+	}}
+
+	tests := []struct {
+		name   string
+		binary *Binary
+	}{
+		{"optional", &Binary{Name: "go", Version: "~1.21", Optional: true}},
+		{"on_no_match", &Binary{Name: "go", Version: "~1.21", OnNoMatch: OnNoMatchSkip}},
+		{"comparator", &Binary{Name: "protoc", Version: "~25", Comparator: "lexical"}},
+		{"probe", &Binary{Name: "go", Version: "~1.21", Probe: "/bin/fake-go-version.sh"}},
+		{"version_env", &Binary{Name: "go", Version: "~1.21", VersionEnv: "GO_VERSION"}},
+	}
+	for _, tt := range tests {
+		local := &Config{Binary: []*Binary{tt.binary}}
+		if _, err := MergeBaseline(baseline, local); !errors.Is(err, ErrLooserThanBaseline) {
+			t.Errorf("MergeBaseline with %s error = %v, want %v", tt.name, err, ErrLooserThanBaseline)
+		}
+	}
+
+	// A local binary that drops an excluded version still excludes it, without modifying the
+	// local config.
+	local := &Config{Binary: []*Binary{{Name: "go", Version: "~1.21.2", Exclude: []string{"1.21.4"}}}}
+	merged, err := MergeBaseline(baseline, local)
+	if err != nil {
+		t.Fatalf("MergeBaseline returned error: %v", err)
+	}
+	if exclude := merged.Binary[0].Exclude; len(exclude) != 2 || exclude[0] != "1.21.4" || exclude[1] != "1.21.1" {
+		t.Errorf("merged go exclude = %v, want [1.21.4 1.21.1]", exclude)
+	}
+	if len(local.Binary[0].Exclude) != 1 {
+		t.Errorf("local go exclude = %v, want it unchanged", local.Binary[0].Exclude)
+	}
+}
+
+func TestMergeBaselineDuplicates(t *testing.T) {
+	baseline := &Config{Binary: []*Binary{
+		{Name: "go", Version: "~1.21"},
+	}}
+
+	// Both local blocks are kept, and each must be at least as strict as the baseline.
+	local := &Config{Binary: []*Binary{
+		{Name: "go", Version: "~1.21.3"},
+		{Name: "go", Version: "~1.21.4", Path: "/usr/local/go/bin/go"},
+	}}
+	merged, err := MergeBaseline(baseline, local)
+	if err != nil {
+		t.Fatalf("MergeBaseline returned error: %v", err)
+	}
+	if len(merged.Binary) != 2 || merged.Binary[0].Version != "~1.21.3" || merged.Binary[1].Path != "/usr/local/go/bin/go" {
+		t.Errorf("MergeBaseline binaries = %+v, want both go binaries", merged.Binary)
+	}
+
+	local.Binary[1].Version = ">= 1.17"
+	if _, err := MergeBaseline(baseline, local); !errors.Is(err, ErrLooserThanBaseline) {
+		t.Errorf("MergeBaseline error = %v, want %v", err, ErrLooserThanBaseline)
+	}
+}
+
+func TestParseGoDirective(t *testing.T) {
+	tests := []struct {
+		gomod    string
+		expected string
+	}{
+		{"module example.com/m\n\ngo 1.21\n", "1.21"},
+		{"module example.com/m\n\ngo 1.21.0\n\ntoolchain go1.21.3\n", "1.21.0"},
+		{"module example.com/m\ngo 1.19 // comment\n", "1.19"},
+	}
+
+	for _, test := range tests {
+		actual, err := ParseGoDirective(strings.NewReader(test.gomod))
+		if err != nil {
+			t.Errorf("ParseGoDirective(%q) returned error: %v", test.gomod, err)
+			continue
+		}
+		if actual != test.expected {
+			t.Errorf("ParseGoDirective(%q) = %s, want %s", test.gomod, actual, test.expected)
+		}
+	}
+
+	_, err := ParseGoDirective(strings.NewReader("module example.com/m\n"))
+	if !errors.Is(err, ErrNoGoDirective) {
+		t.Errorf("ParseGoDirective error = %v, want %v", err, ErrNoGoDirective)
+	}
+}
+
+func TestLoadConfigGoModVersion(t *testing.T) {
+	zlog := zerolog.Nop()
+	cfg, err := LoadConfig("testdata/gomod/nested/version-enforcer.hcl", &zlog)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+	if cfg.Binary[0].Version != ">= 1.21.0" {
+		t.Errorf("go version = %s, want %s", cfg.Binary[0].Version, ">= 1.21.0")
+	}
+}
+
+func TestLoadConfigVersionArgs(t *testing.T) {
+	zlog := zerolog.Nop()
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "version-enforcer.hcl")
+	writeFile(t, path, "binary \"git\" {\n  version = \"~2\"\n  version_args = [\"version\"]\n}\n")
+	cfg, err := LoadConfig(path, &zlog)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+	if strings.Join(cfg.Binary[0].VersionArgs, " ") != "version" {
+		t.Errorf("version_args = %v, want [version]", cfg.Binary[0].VersionArgs)
+	}
+
+	writeFile(t, path, "binary \"git\" {\n  version = \"~2\"\n  version_args = []\n}\n")
+	if _, err := LoadConfig(path, &zlog); !errors.Is(err, ErrEmptyVersionArgs) {
+		t.Errorf("LoadConfig error = %v, want %v", err, ErrEmptyVersionArgs)
+	}
+}
+
+func TestLoadConfigMinMaxVersion(t *testing.T) {
+	zlog := zerolog.Nop()
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "version-enforcer.hcl")
+	writeFile(t, path, "binary \"go\" {\n  min_version = \"1.20\"\n  max_version = \"1.22\"\n}\n")
+	cfg, err := LoadConfig(path, &zlog)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+	if actual := cfg.Binary[0].RequirementString(); actual != ">=1.20 <1.22" {
+		t.Errorf("RequirementString() = %s, want %s", actual, ">=1.20 <1.22")
+	}
+
+	writeFile(t, path, "binary \"go\" {\n  min_version = \"1.22\"\n  max_version = \"1.20\"\n}\n")
+	if _, err := LoadConfig(path, &zlog); !errors.Is(err, identifier.ErrInvalidRange) {
+		t.Errorf("LoadConfig error = %v, want %v", err, identifier.ErrInvalidRange)
+	}
+
+	writeFile(t, path, "binary \"go\" {\n  version = \"~1.21\"\n  max_version = \"1.22\"\n}\n")
+	if _, err := LoadConfig(path, &zlog); !errors.Is(err, ErrConflictingVersion) {
+		t.Errorf("LoadConfig error = %v, want %v", err, ErrConflictingVersion)
+	}
+
+	writeFile(t, path, "binary \"go\" {\n}\n")
+	if _, err := LoadConfig(path, &zlog); !errors.Is(err, ErrMissingVersion) {
+		t.Errorf("LoadConfig error = %v, want %v", err, ErrMissingVersion)
+	}
+}
+
+func TestLoadConfigVersions(t *testing.T) {
+	zlog := zerolog.Nop()
+	path := filepath.Join(t.TempDir(), "version-enforcer.hcl")
+
+	tests := []struct {
+		config      string
+		requirement string
+		satisfied   map[string]bool
+	}{
+		{
+			config:      "binary \"go\" {\n  versions = [\">= 1.2\", \"< 1.5\"]\n}\n",
+			requirement: ">= 1.2 < 1.5",
+			satisfied:   map[string]bool{"1.1.0": false, "1.2.0": true, "1.4.9": true, "1.5.0": false},
+		},
+		{
+			config:      "binary \"go\" {\n  version = \">= 1.2\"\n}\n",
+			requirement: ">= 1.2",
+			satisfied:   map[string]bool{"1.1.0": false, "1.2.0": true, "1.4.9": true, "1.5.0": true},
+		},
+	}
+	for _, tt := range tests {
+		writeFile(t, path, tt.config)
+		cfg, err := LoadConfig(path, &zlog)
+		if err != nil {
+			t.Fatalf("LoadConfig returned error: %v", err)
+		}
+		binary := cfg.Binary[0]
+		if actual := binary.RequirementString(); actual != tt.requirement {
+			t.Errorf("RequirementString() = %s, want %s", actual, tt.requirement)
+		}
+		for version, want := range tt.satisfied {
+			if satisfied, err := binary.Satisfies(version); err != nil || satisfied != want {
+				t.Errorf("%s: Satisfies(%s) = %t, %v, want %t", tt.requirement, version, satisfied, err, want)
+			}
+			// The requirement as shown to users means the same as the config.
+			if satisfied, err := identifier.SatisfiesE(version, binary.RequirementString()); err != nil || satisfied != want {
+				t.Errorf("SatisfiesE(%s, %s) = %t, %v, want %t", version, binary.RequirementString(), satisfied, err, want)
+			}
+		}
+	}
+
+	writeFile(t, path, "binary \"go\" {\n  versions = [\">= 1.2\", \"< 1.5\"]\n}\n")
+	cfg, err := LoadConfig(path, &zlog)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+	for version, want := range map[string]int{"1.1.0": -1, "1.3.0": 0, "1.5.0": 1} {
+		if c, err := cfg.Binary[0].CompareToRequirement(version); err != nil || c != want {
+			t.Errorf("CompareToRequirement(%s) = %d, %v, want %d", version, c, err, want)
+		}
+	}
+
+	writeFile(t, path, "binary \"go\" {\n  version = \">= 1.2\"\n  versions = [\"< 1.5\"]\n}\n")
+	if _, err := LoadConfig(path, &zlog); !errors.Is(err, ErrConflictingVersion) {
+		t.Errorf("LoadConfig error = %v, want %v", err, ErrConflictingVersion)
+	}
+
+	writeFile(t, path, "binary \"go\" {\n  versions = [\">= 1.2\", \"<< 1.5\"]\n}\n")
+	if _, err := LoadConfig(path, &zlog); !errors.Is(err, identifier.ErrInvalidOperator) {
+		t.Errorf("LoadConfig error = %v, want %v", err, identifier.ErrInvalidOperator)
+	}
+}
+
+func TestLoadConfigProbe(t *testing.T) {
+	zlog := zerolog.Nop()
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "version-enforcer.hcl")
+	probe := filepath.Join(dir, "get-mytool-version.sh")
+	writeFile(t, path, "binary \"mytool\" {\n  version = \"~1.4\"\n  probe = \"./get-mytool-version.sh\"\n}\n")
+
+	writeFile(t, probe, "#!/bin/sh\necho 1.4.2\n")
+	if _, err := LoadConfig(path, &zlog); !errors.Is(err, ErrProbeNotExecutable) {
+		t.Errorf("LoadConfig error = %v, want %v", err, ErrProbeNotExecutable)
+	}
+
+	if err := os.Chmod(probe, 0o755); err != nil {
+		t.Fatalf("failed to chmod %s: %v", probe, err)
+	}
+	cfg, err := LoadConfig(path, &zlog)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+	if cfg.Binary[0].Probe != probe {
+		t.Errorf("probe = %s, want %s", cfg.Binary[0].Probe, probe)
+	}
+}
+
+func TestLoadConfigComparator(t *testing.T) {
+	zlog := zerolog.Nop()
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "version-enforcer.hcl")
+	writeFile(t, path, "binary \"tzdata\" {\n  version = \">= 2023c\"\n  version_env = \"TZDATA_VERSION\"\n  comparator = \"date\"\n}\n")
+	cfg, err := LoadConfig(path, &zlog)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+	if satisfied, err := cfg.Binary[0].Satisfies("2024a"); err != nil || !satisfied {
+		t.Errorf("Satisfies(2024a) = %t, %v, want true", satisfied, err)
+	}
+	if satisfied, err := cfg.Binary[0].Satisfies("2023b"); err != nil || satisfied {
+		t.Errorf("Satisfies(2023b) = %t, %v, want false", satisfied, err)
+	}
+
+	writeFile(t, path, "binary \"tzdata\" {\n  version = \">= 2023c\"\n  version_env = \"TZDATA_VERSION\"\n  comparator = \"calver\"\n}\n")
+	if _, err := LoadConfig(path, &zlog); !errors.Is(err, identifier.ErrUnknownComparator) {
+		t.Errorf("LoadConfig error = %v, want %v", err, identifier.ErrUnknownComparator)
+	}
+}
+
+func TestLoadConfigSchemaVersion(t *testing.T) {
+	zlog := zerolog.Nop()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "version-enforcer.hcl")
+
+	tests := []struct {
+		schemaVersion string
+		expected      error
+	}{
+		{"", nil},
+		{"schema_version = 0\n", nil},
+		{"schema_version = 1\n", nil},
+		{"schema_version = 2\n", ErrSchemaTooNew},
+	}
+	for _, test := range tests {
+		writeFile(t, path, test.schemaVersion+"binary \"git\" {\n  version = \"~2\"\n}\n")
+		_, err := LoadConfig(path, &zlog)
+		if !errors.Is(err, test.expected) {
+			t.Errorf("LoadConfig(%q) error = %v, want %v", test.schemaVersion, err, test.expected)
+		}
+	}
+}
+
+func TestLoadConfigGlob(t *testing.T) {
+	zlog := zerolog.Nop()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "version-enforcer.hcl")
+
+	writeFile(t, path, "binary \"go1.*\" {\n  version = \">= 1.20\"\n  program = \"go\"\n  on_no_match = \"skip\"\n}\n")
+	cfg, err := LoadConfig(path, &zlog)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+	if !cfg.Binary[0].IsGlob() || cfg.Binary[0].ProgramName() != "go" {
+		t.Errorf("binary = %+v, want a glob identified as go", cfg.Binary[0])
+	}
+
+	writeFile(t, path, "binary \"go1.*\" {\n  version = \">= 1.20\"\n}\n")
+	if _, err := LoadConfig(path, &zlog); !errors.Is(err, ErrGlobRequiresProgram) {
+		t.Errorf("LoadConfig error = %v, want %v", err, ErrGlobRequiresProgram)
+	}
+
+	writeFile(t, path, "binary \"go1.*\" {\n  version = \">= 1.20\"\n  program = \"go\"\n  on_no_match = \"ignore\"\n}\n")
+	if _, err := LoadConfig(path, &zlog); !errors.Is(err, ErrUnknownOnNoMatch) {
+		t.Errorf("LoadConfig error = %v, want %v", err, ErrUnknownOnNoMatch)
+	}
+}
+
+func TestLoadConfigLatestSource(t *testing.T) {
+	zlog := zerolog.Nop()
+	path := filepath.Join(t.TempDir(), "version-enforcer.hcl")
+
+	writeFile(t, path, "binary \"terraform\" {\n  version = \">= 1.5\"\n  latest_source = \"https://api.github.com/repos/hashicorp/terraform/releases/latest\"\n  on_outdated = \"error\"\n}\n")
+	cfg, err := LoadConfig(path, &zlog)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+	if cfg.Binary[0].LatestSource == "" || cfg.Binary[0].OnOutdated != OnOutdatedError {
+		t.Errorf("binary = %+v, want a latest_source that errors when outdated", cfg.Binary[0])
+	}
+
+	writeFile(t, path, "binary \"terraform\" {\n  version = \">= 1.5\"\n  latest_source = \"https://example.com/latest\"\n  on_outdated = \"fail\"\n}\n")
+	if _, err := LoadConfig(path, &zlog); !errors.Is(err, ErrUnknownOnOutdated) {
+		t.Errorf("LoadConfig error = %v, want %v", err, ErrUnknownOnOutdated)
+	}
+
+	writeFile(t, path, "binary \"terraform\" {\n  version = \">= 1.5\"\n  latest_source = \"releases/latest\"\n}\n")
+	if _, err := LoadConfig(path, &zlog); !errors.Is(err, ErrInvalidLatestSource) {
+		t.Errorf("LoadConfig error = %v, want %v", err, ErrInvalidLatestSource)
+	}
+}
+
+func TestLoadConfigDiagnosticsNotOnStdout(t *testing.T) {
+	var logs bytes.Buffer
+	zlog := zerolog.New(&logs)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "version-enforcer.hcl")
+	writeFile(t, path, "binary \"go\" {\n  version = \n}\n")
+
+	stdout, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdout.Close()
+	defer func(original *os.File) { os.Stdout = original }(os.Stdout)
+	os.Stdout = stdout
+
+	if _, err := LoadConfig(path, &zlog); err == nil {
+		t.Fatalf("LoadConfig returned no error for invalid HCL")
+	}
+	printed, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(printed) != 0 {
+		t.Errorf("stdout = %q, want nothing, so that it only has results", printed)
+	}
+	if !strings.Contains(logs.String(), "version-enforcer.hcl") {
+		t.Errorf("logs = %q, want the diagnostic for version-enforcer.hcl", logs.String())
+	}
+}
+
+func writeFile(t *testing.T, path string, contents string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/gohcl"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+	"path/filepath"
+	"strings"
+)
+
+// Formats that a config can be written in. FormatAuto detects the format from the extension of the
+// config file. FormatJSON is HCL's JSON syntax, e.g. {"binary": {"go": {"version": "~1.21"}}}.
+const (
+	FormatAuto = ""
+	FormatHCL  = "hcl"
+	FormatJSON = "json"
+	FormatTOML = "toml"
+	FormatYAML = "yaml"
+)
+
+var (
+	ErrUnknownConfigFormat = errors.New("unknown config format")
+	ErrMissingName         = errors.New("binary must have a name")
+)
+
+// configFormat returns format, or if it is FormatAuto the format of the config at configPath
+// according to its extension.
+func configFormat(configPath string, format string) (string, error) {
+	switch format {
+	case FormatHCL, FormatJSON, FormatTOML, FormatYAML:
+		return format, nil
+	case FormatAuto:
+	default:
+		return "", fmt.Errorf("%w %q, want %s, %s, %s, or %s", ErrUnknownConfigFormat, format, FormatHCL, FormatJSON, FormatTOML, FormatYAML)
+	}
+	switch strings.ToLower(filepath.Ext(configPath)) {
+	case ".hcl":
+		return FormatHCL, nil
+	case ".json":
+		return FormatJSON, nil
+	case ".toml":
+		return FormatTOML, nil
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	default:
+		return "", fmt.Errorf("%w: cannot tell the format of %s from its extension; set the format explicitly", ErrUnknownConfigFormat, configPath)
+	}
+}
+
+// decodeConfig decodes src, a config named filename in format, into cfg. HCL errors are returned as
+// hcl.Diagnostics.
+func decodeConfig(src []byte, filename string, format string, cfg *Config) error {
+	switch format {
+	case FormatYAML:
+		return decodeYAML(src, cfg)
+	case FormatTOML:
+		return decodeTOML(src, cfg)
+	}
+	parser := hclparse.NewParser()
+	var file *hcl.File
+	var diagnostics hcl.Diagnostics
+	if format == FormatJSON {
+		file, diagnostics = parser.ParseJSON(src, filename)
+	} else {
+		file, diagnostics = parser.ParseHCL(src, filename)
+	}
+	if diagnostics.HasErrors() {
+		return diagnostics
+	}
+	if diagnostics := gohcl.DecodeBody(file.Body, nil, cfg); diagnostics.HasErrors() {
+		return diagnostics
+	}
+	return nil
+}
+
+// decodeYAML decodes a YAML config, in which binaries are a list with their name as a field, e.g.
+//
+//	binary:
+//	  - name: go
+//	    version: "~1.21"
+//
+// As in HCL, unknown fields are an error.
+func decodeYAML(src []byte, cfg *Config) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(src))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil {
+		return err
+	}
+	return checkNames(cfg)
+}
+
+// decodeTOML decodes a TOML config, in which binaries are an array of tables with their name as a
+// field, e.g.
+//
+//	[[binary]]
+//	name = "go"
+//	version = "~1.21"
+//
+// As in HCL, unknown fields are an error.
+func decodeTOML(src []byte, cfg *Config) error {
+	decoder := toml.NewDecoder(bytes.NewReader(src))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return err
+	}
+	return checkNames(cfg)
+}
+
+// checkNames checks that every binary of a YAML or TOML config has a name, which HCL requires as
+// the label of the block.
+func checkNames(cfg *Config) error {
+	for i, binary := range cfg.Binary {
+		if binary == nil || binary.Name == "" {
+			return fmt.Errorf("%w: binary %d", ErrMissingName, i+1)
+		}
+	}
+	return nil
+}
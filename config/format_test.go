@@ -0,0 +1,177 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"errors"
+	"github.com/rs/zerolog"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadConfigFormat(t *testing.T) {
+	zlog := zerolog.Nop()
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "tools.conf")
+	writeFile(t, path, `
+assert_equal: [cmake, ctest]
+binary:
+  - name: cmake
+    version: "~3.27"
+  - name: ctest
+    version: "~3.27"
+  - name: telnet
+    absent: true
+`)
+	if _, err := LoadConfig(path, &zlog); !errors.Is(err, ErrUnknownConfigFormat) {
+		t.Errorf("LoadConfig(tools.conf) error = %v, want %v", err, ErrUnknownConfigFormat)
+	}
+	cfg, err := LoadConfigFormat(path, FormatYAML, &zlog)
+	if err != nil {
+		t.Fatalf("LoadConfigFormat(tools.conf, yaml) returned error: %v", err)
+	}
+	if len(cfg.Binary) != 3 || cfg.Binary[1].Name != "ctest" || cfg.Binary[1].Version != "~3.27" || !cfg.Binary[2].Absent {
+		t.Errorf("LoadConfigFormat(tools.conf, yaml) binaries = %+v, want cmake, ctest, and telnet", cfg.Binary)
+	}
+	if len(cfg.AssertEqual) != 2 {
+		t.Errorf("AssertEqual = %v, want [cmake ctest]", cfg.AssertEqual)
+	}
+
+	// The extension is enough without the override.
+	yamlPath := filepath.Join(dir, "version-enforcer.yml")
+	writeFile(t, yamlPath, "binary:\n  - name: go\n    version: \"~1.21\"\n")
+	if _, err := LoadConfig(yamlPath, &zlog); err != nil {
+		t.Errorf("LoadConfig(version-enforcer.yml) returned error: %v", err)
+	}
+
+	jsonPath := filepath.Join(dir, "tools.conf.json")
+	writeFile(t, jsonPath, `{"binary": {"go": {"version": "~1.21"}}}`)
+	if cfg, err := LoadConfig(jsonPath, &zlog); err != nil || len(cfg.Binary) != 1 || cfg.Binary[0].Name != "go" {
+		t.Errorf("LoadConfig(tools.conf.json) = %+v, %v, want go", cfg, err)
+	}
+	if _, err := LoadConfigFormat(jsonPath, FormatHCL, &zlog); err == nil {
+		t.Errorf("LoadConfigFormat(tools.conf.json, hcl) returned no error")
+	}
+
+	if _, err := LoadConfigFormat(path, "ini", &zlog); !errors.Is(err, ErrUnknownConfigFormat) {
+		t.Errorf("LoadConfigFormat(ini) error = %v, want %v", err, ErrUnknownConfigFormat)
+	}
+}
+
+func TestLoadConfigTOML(t *testing.T) {
+	zlog := zerolog.Nop()
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "version-enforcer.toml")
+	writeFile(t, path, `# Tools for the build.
+schema_version = 1
+assert_equal = [
+  "cmake",
+  'ctest', # trailing commas are allowed
+]
+
+[[binary]]
+name = "cmake"
+version = "~3.27"
+
+[[binary]]
+name = "ctest"
+version = "~3.27"
+exclude = ["3.27.0"]
+
+[[binary]]
+name = "telnet"
+absent = true
+`)
+	cfg, err := LoadConfig(path, &zlog)
+	if err != nil {
+		t.Fatalf("LoadConfig(version-enforcer.toml) returned error: %v", err)
+	}
+	if len(cfg.Binary) != 3 || cfg.Binary[1].Name != "ctest" || cfg.Binary[1].Version != "~3.27" || !cfg.Binary[2].Absent {
+		t.Errorf("LoadConfig(version-enforcer.toml) binaries = %+v, want cmake, ctest, and telnet", cfg.Binary)
+	}
+	if len(cfg.Binary[1].Exclude) != 1 || cfg.Binary[1].Exclude[0] != "3.27.0" {
+		t.Errorf("ctest Exclude = %v, want [3.27.0]", cfg.Binary[1].Exclude)
+	}
+	if len(cfg.AssertEqual) != 2 || cfg.AssertEqual[1] != "ctest" {
+		t.Errorf("AssertEqual = %v, want [cmake ctest]", cfg.AssertEqual)
+	}
+
+	// The override works for any extension.
+	confPath := filepath.Join(dir, "tools.conf")
+	writeFile(t, confPath, "[[binary]]\nname = \"go\"\nversion = \"~1.21\"\n")
+	if cfg, err := LoadConfigFormat(confPath, FormatTOML, &zlog); err != nil || len(cfg.Binary) != 1 || cfg.Binary[0].Name != "go" {
+		t.Errorf("LoadConfigFormat(tools.conf, toml) = %+v, %v, want go", cfg, err)
+	}
+}
+
+func TestLoadConfigTOMLErrors(t *testing.T) {
+	zlog := zerolog.Nop()
+	path := filepath.Join(t.TempDir(), "version-enforcer.toml")
+
+	writeFile(t, path, "[[binary]]\nversion = \"~1.21\"\n")
+	if _, err := LoadConfig(path, &zlog); !errors.Is(err, ErrMissingName) {
+		t.Errorf("LoadConfig error = %v, want %v", err, ErrMissingName)
+	}
+
+	for _, contents := range []string{
+		"[[binary]]\nname = \"go\"\nversion = \"~1.21\n",
+		"[[binary]]\nname = \"go\"\nname = \"go\"\n",
+		"[[binary]]\nname = \"go\"\nversoin = \"~1.21\"\n",
+	} {
+		writeFile(t, path, contents)
+		if _, err := LoadConfig(path, &zlog); err == nil {
+			t.Errorf("LoadConfig(%q) returned no error", contents)
+		}
+	}
+}
+
+func TestLoadConfigBytes(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	cfg, err := LoadConfigBytes([]byte("binary \"go\" {\n  version = \"~1.21\"\n}\n"), FormatAuto, &zlog)
+	if err != nil || len(cfg.Binary) != 1 || cfg.Binary[0].Name != "go" {
+		t.Errorf("LoadConfigBytes(hcl) = %+v, %v, want go", cfg, err)
+	}
+
+	// Any TOML syntax works, such as inline tables and multi-line strings.
+	cfg, err = LoadConfigBytes([]byte("binary = [{name = \"go\", version = \"~1.21\", install_hint = \"\"\"\nbrew install go\"\"\"}]\n"), FormatTOML, &zlog)
+	if err != nil || len(cfg.Binary) != 1 || cfg.Binary[0].Version != "~1.21" || cfg.Binary[0].InstallHint != "brew install go" {
+		t.Errorf("LoadConfigBytes(toml) = %+v, %v, want go ~1.21", cfg, err)
+	}
+
+	if _, err := LoadConfigBytes([]byte("binary:\n  - name: go\n"), "ini", &zlog); !errors.Is(err, ErrUnknownConfigFormat) {
+		t.Errorf("LoadConfigBytes(ini) error = %v, want %v", err, ErrUnknownConfigFormat)
+	}
+}
+
+func TestLoadConfigYAMLErrors(t *testing.T) {
+	zlog := zerolog.Nop()
+	path := filepath.Join(t.TempDir(), "version-enforcer.yaml")
+
+	writeFile(t, path, "binary:\n  - version: \"~1.21\"\n")
+	if _, err := LoadConfig(path, &zlog); !errors.Is(err, ErrMissingName) {
+		t.Errorf("LoadConfig error = %v, want %v", err, ErrMissingName)
+	}
+
+	writeFile(t, path, "binary:\n  - name: go\n    versoin: \"~1.21\"\n")
+	if _, err := LoadConfig(path, &zlog); err == nil {
+		t.Errorf("LoadConfig returned no error for an unknown field")
+	}
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/asimihsan/version-enforcer/identifier"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GoModVersion is a special version for the go binary that reads the requirement from the go
+// directive of the nearest go.mod, treating it as a minimum version.
+const GoModVersion = "go.mod"
+
+var (
+	ErrGoModNotFound     = errors.New("go.mod not found")
+	ErrNoGoDirective     = errors.New("go.mod has no go directive")
+	ErrGoModVersionUsage = errors.New(`version = "go.mod" is only supported for the go binary`)
+)
+
+// resolveGoModVersion returns a ">=" requirement for the go directive of the nearest go.mod in dir
+// or its parents.
+func resolveGoModVersion(dir string) (string, error) {
+	path, err := findGoMod(dir)
+	if err != nil {
+		return "", err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	version, err := ParseGoDirective(f)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return ">= " + version, nil
+}
+
+// findGoMod returns the path of the go.mod in dir, or in the closest parent of dir that has one.
+func findGoMod(dir string) (string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		path := filepath.Join(dir, "go.mod")
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", ErrGoModNotFound
+		}
+		dir = parent
+	}
+}
+
+// ParseGoDirective returns the version in the go directive of a go.mod file, e.g. "1.21" for
+// "go 1.21" or "1.21.0" for "go 1.21.0".
+func ParseGoDirective(r io.Reader) (string, error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "go" {
+			continue
+		}
+
+		if _, err := identifier.ParseVersion(fields[1]); err != nil {
+			return "", fmt.Errorf("invalid go directive %q: %w", fields[1], err)
+		}
+		return fields[1], nil
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	return "", ErrNoGoDirective
+}
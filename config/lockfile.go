@@ -0,0 +1,186 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"github.com/asimihsan/version-enforcer/identifier"
+	"github.com/rs/zerolog"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// DefaultLockPath is where `enforce lock` writes the lockfile by default.
+const DefaultLockPath = "tool-enforcer.lock"
+
+var (
+	ErrInvalidLock = errors.New("invalid lockfile")
+)
+
+// Lock maps binary names to the exact versions detected when the lockfile was written.
+//
+// The lockfile format is one "name=version" entry per line. Blank lines and lines starting with
+// "#" are ignored, e.g.
+//
+//	# Generated by `enforce lock`. Do not edit.
+//	git=2.39.1
+//	go=1.21.3
+type Lock map[string]string
+
+// LoadLock reads the lockfile at path.
+func LoadLock(path string) (Lock, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseLock(f)
+}
+
+// ParseLock parses a lockfile.
+func ParseLock(r io.Reader) (Lock, error) {
+	return parseLock(r, nil)
+}
+
+// LoadPins reads a central pins file of pinned versions at path, such as versions.pins, which has
+// the same format as the lockfile written by `enforce lock`.
+func LoadPins(path string, zlog *zerolog.Logger) (Lock, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		zlog.Error().Err(err).Str("path", path).Msg("failed to open pins file")
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParsePins(f, zlog)
+}
+
+// ParsePins is like ParseLock, but logs a warning for each name that is pinned more than once. As
+// with ParseLock, the last version wins.
+func ParsePins(r io.Reader, zlog *zerolog.Logger) (Lock, error) {
+	return parseLock(r, func(name string, lineNumber int) {
+		zlog.Warn().Str("name", name).Int("line", lineNumber).Msg("name is pinned more than once, using the last version")
+	})
+}
+
+// parseLock parses a lockfile, calling duplicate, if not nil, for each entry whose name was already
+// listed.
+func parseLock(r io.Reader, duplicate func(name string, lineNumber int)) (Lock, error) {
+	lock := make(Lock)
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, version, ok := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		version = strings.TrimSpace(version)
+		if !ok || name == "" || version == "" {
+			return nil, fmt.Errorf("%w: line %d: expected name=version, got %q", ErrInvalidLock, lineNumber, line)
+		}
+		if _, ok := lock[name]; ok && duplicate != nil {
+			duplicate(name, lineNumber)
+		}
+		lock[name] = version
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return lock, nil
+}
+
+// ApplyPins requires the exact version of each binary in pins. A pin overrides the requirement of a
+// binary that the config already configures, and otherwise adds the binary, in which case it must
+// be a supported program.
+func (c *Config) ApplyPins(pins Lock) error {
+	names := make([]string, 0, len(pins))
+	for name := range pins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	configured := make(map[string][]*Binary)
+	for _, binary := range c.Binary {
+		configured[binary.Name] = append(configured[binary.Name], binary)
+	}
+	for _, name := range names {
+		binaries, ok := configured[name]
+		if !ok {
+			if _, err := identifier.GetProgram(name); err != nil {
+				return fmt.Errorf("%w: %s is not a supported program", ErrInvalidLock, name)
+			}
+			binary := &Binary{Name: name}
+			c.Binary = append(c.Binary, binary)
+			binaries = []*Binary{binary}
+		}
+		for _, binary := range binaries {
+			if binary.Absent {
+				return fmt.Errorf("%w: %s must be absent", ErrInvalidLock, name)
+			}
+			binary.Version, binary.Versions, binary.MinVersion, binary.MaxVersion = pins[name], nil, "", ""
+			if err := binary.checkRequirement(); err != nil {
+				return fmt.Errorf("%w: %s: %v", ErrInvalidLock, name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// SaveLock writes lock to the lockfile at path, replacing it if it exists.
+func SaveLock(path string, lock Lock) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if err := WriteLock(f, lock); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteLock writes lock in the lockfile format, with entries sorted by name so that the output is
+// stable.
+func WriteLock(w io.Writer, lock Lock) error {
+	names := make([]string, 0, len(lock))
+	for name := range lock {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if _, err := fmt.Fprintln(w, "# Generated by `enforce lock`. Do not edit."); err != nil {
+		return err
+	}
+	for _, name := range names {
+		if _, err := fmt.Fprintf(w, "%s=%s\n", name, lock[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"github.com/rs/zerolog"
+	"strings"
+	"testing"
+)
+
+func TestLockRoundTrip(t *testing.T) {
+	lock := Lock{"go": "1.21.3", "git": "2.39.1"}
+
+	var buf bytes.Buffer
+	if err := WriteLock(&buf, lock); err != nil {
+		t.Fatalf("WriteLock returned error: %v", err)
+	}
+	expected := "# Generated by `enforce lock`. Do not edit.\ngit=2.39.1\ngo=1.21.3\n"
+	if buf.String() != expected {
+		t.Errorf("WriteLock wrote %q, want %q", buf.String(), expected)
+	}
+
+	parsed, err := ParseLock(&buf)
+	if err != nil {
+		t.Fatalf("ParseLock returned error: %v", err)
+	}
+	if len(parsed) != len(lock) || parsed["go"] != "1.21.3" || parsed["git"] != "2.39.1" {
+		t.Errorf("ParseLock = %v, want %v", parsed, lock)
+	}
+
+	if _, err := ParseLock(strings.NewReader("go 1.21.3\n")); !errors.Is(err, ErrInvalidLock) {
+		t.Errorf("ParseLock error = %v, want %v", err, ErrInvalidLock)
+	}
+}
+
+func TestParsePins(t *testing.T) {
+	var logs bytes.Buffer
+	zlog := zerolog.New(&logs)
+
+	input := "# Pinned by the platform team\n" +
+		"\n" +
+		"go=1.21.3\n" +
+		"  terraform = 1.5.7  \n" +
+		"go=1.21.5\n"
+	pins, err := ParsePins(strings.NewReader(input), &zlog)
+	if err != nil {
+		t.Fatalf("ParsePins returned error: %v", err)
+	}
+	if len(pins) != 2 || pins["go"] != "1.21.5" || pins["terraform"] != "1.5.7" {
+		t.Errorf("ParsePins = %v, want go=1.21.5 and terraform=1.5.7", pins)
+	}
+	if !strings.Contains(logs.String(), `"name":"go","line":5`) {
+		t.Errorf("ParsePins logged %q, want a warning about go on line 5", logs.String())
+	}
+
+	if _, err := ParsePins(strings.NewReader("go\n"), &zlog); !errors.Is(err, ErrInvalidLock) {
+		t.Errorf("ParsePins error = %v, want %v", err, ErrInvalidLock)
+	}
+}
+
+func TestApplyPins(t *testing.T) {
+	cfg := &Config{Binary: []*Binary{
+		{Name: "go", MinVersion: "1.20"},
+		{Name: "git", Version: "~2"},
+	}}
+	if err := cfg.ApplyPins(Lock{"go": "1.21.3", "terraform": "1.5.7"}); err != nil {
+		t.Fatalf("ApplyPins returned error: %v", err)
+	}
+
+	got := make([]string, 0, len(cfg.Binary))
+	for _, binary := range cfg.Binary {
+		got = append(got, binary.Name+" "+binary.RequirementString())
+	}
+	want := "go 1.21.3, git ~2, terraform 1.5.7"
+	if strings.Join(got, ", ") != want {
+		t.Errorf("ApplyPins config = %s, want %s", strings.Join(got, ", "), want)
+	}
+
+	if err := cfg.ApplyPins(Lock{"not-a-program": "1.0.0"}); !errors.Is(err, ErrInvalidLock) {
+		t.Errorf("ApplyPins error = %v, want %v", err, ErrInvalidLock)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"github.com/asimihsan/version-enforcer/identifier"
+	"github.com/rs/zerolog"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"strings"
+)
+
+// preCommitRepoToProgramName maps pre-commit hook repos whose rev is the version of a program we
+// can identify to that program's name.
+var preCommitRepoToProgramName = map[string]string{
+	"https://github.com/bufbuild/buf":                  "buf",
+	"https://github.com/EmbarkStudios/cargo-deny":      "cargo-deny",
+	"https://github.com/python-poetry/poetry":          "poetry",
+	"https://github.com/terraform-docs/terraform-docs": "terraform-docs",
+}
+
+// preCommitConfig is the part of a .pre-commit-config.yaml file that we read.
+type preCommitConfig struct {
+	Repos []struct {
+		Repo string `yaml:"repo"`
+		Rev  string `yaml:"rev"`
+	} `yaml:"repos"`
+}
+
+// LoadPreCommitConfig loads binaries from a .pre-commit-config.yaml file. See
+// ParsePreCommitConfig.
+func LoadPreCommitConfig(path string, zlog *zerolog.Logger) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		zlog.Error().Err(err).Str("path", path).Msg("failed to open pre-commit config")
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParsePreCommitConfig(f, zlog)
+}
+
+// ParsePreCommitConfig parses a .pre-commit-config.yaml file, e.g.
+//
+//	repos:
+//	  - repo: https://github.com/bufbuild/buf
+//	    rev: v1.23.1
+//	    hooks:
+//	      - id: buf-lint
+//
+// The rev of each hook repo that is a supported program becomes an exact requirement. Repos that
+// are not supported programs, and revs that are not versions (e.g. commit hashes), are skipped
+// with a warning. The "local" and "meta" repos are skipped silently.
+func ParsePreCommitConfig(r io.Reader, zlog *zerolog.Logger) (*Config, error) {
+	var preCommit preCommitConfig
+	if err := yaml.NewDecoder(r).Decode(&preCommit); err != nil && err != io.EOF {
+		zlog.Error().Err(err).Msg("failed to decode pre-commit config")
+		return nil, err
+	}
+
+	var cfg Config
+	for _, repo := range preCommit.Repos {
+		if repo.Repo == "local" || repo.Repo == "meta" {
+			continue
+		}
+
+		name, ok := preCommitRepoToProgramName[strings.TrimSuffix(repo.Repo, ".git")]
+		if !ok {
+			zlog.Warn().Str("repo", repo.Repo).Msg("skipping pre-commit repo that is not a supported program")
+			continue
+		}
+		version := strings.TrimPrefix(repo.Rev, "v")
+		if _, err := identifier.NewRequirement(version); err != nil {
+			zlog.Warn().Str("repo", repo.Repo).Str("rev", repo.Rev).Msg("skipping pre-commit rev that is not a version")
+			continue
+		}
+
+		cfg.Binary = append(cfg.Binary, &Binary{
+			Name:    name,
+			Version: version,
+		})
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,45 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"github.com/rs/zerolog"
+	"testing"
+)
+
+func TestLoadPreCommitConfig(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	cfg, err := LoadPreCommitConfig("testdata/precommit/.pre-commit-config.yaml", &zlog)
+	if err != nil {
+		t.Fatalf("LoadPreCommitConfig returned error: %v", err)
+	}
+
+	expected := []Binary{
+		{Name: "buf", Version: "1.23.1"},
+		{Name: "terraform-docs", Version: "0.16.0"},
+	}
+	if len(cfg.Binary) != len(expected) {
+		t.Fatalf("LoadPreCommitConfig returned %d binaries, want %d", len(cfg.Binary), len(expected))
+	}
+	for i, binary := range cfg.Binary {
+		if binary.Name != expected[i].Name || binary.Version != expected[i].Version {
+			t.Errorf("binary %d = %+v, want %+v", i, *binary, expected[i])
+		}
+	}
+}
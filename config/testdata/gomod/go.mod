@@ -0,0 +1,7 @@
+module example.com/sample
+
+go 1.21.0 // the three-part form used since Go 1.21
+
+toolchain go1.21.3
+
+require github.com/rs/zerolog v1.29.0
@@ -0,0 +1,3 @@
+binary "go" {
+  version = "go.mod"
+}
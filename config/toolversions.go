@@ -0,0 +1,98 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"bufio"
+	"github.com/asimihsan/version-enforcer/identifier"
+	"github.com/rs/zerolog"
+	"io"
+	"os"
+	"strings"
+)
+
+// asdfPluginToProgramName maps asdf plugin names to the names of programs we can identify, where
+// they differ.
+var asdfPluginToProgramName = map[string]string{
+	"golang":    "go",
+	"erlang":    "erl",
+	"leiningen": "lein",
+	"protobuf":  "protoc",
+	"dotnet-ef": "dotnet-ef",
+}
+
+// LoadToolVersions loads binaries from an asdf .tool-versions file. See ParseToolVersions.
+func LoadToolVersions(path string, zlog *zerolog.Logger) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		zlog.Error().Err(err).Str("path", path).Msg("failed to open .tool-versions file")
+		return nil, err
+	}
+	defer f.Close()
+
+	return ParseToolVersions(f, zlog)
+}
+
+// ParseToolVersions parses an asdf .tool-versions file, e.g.
+//
+//	# Comments are ignored
+//	golang 1.21.3
+//	nodejs 18.16.0 16.20.0
+//
+// Each pinned version becomes an exact requirement. If a tool lists multiple versions then only
+// the first is used, because that is the one asdf selects. Plugins that do not map to a supported
+// program, and versions that are not numeric (e.g. "system" or "ref:main"), are skipped.
+func ParseToolVersions(r io.Reader, zlog *zerolog.Logger) (*Config, error) {
+	var cfg Config
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		plugin, version := fields[0], fields[1]
+		name := plugin
+		if mapped, ok := asdfPluginToProgramName[plugin]; ok {
+			name = mapped
+		}
+		if _, err := identifier.GetProgram(name); err != nil {
+			zlog.Debug().Str("plugin", plugin).Msg("skipping unsupported .tool-versions plugin")
+			continue
+		}
+		if _, err := identifier.NewRequirement(version); err != nil {
+			zlog.Debug().Str("plugin", plugin).Str("version", version).Msg("skipping non-numeric .tool-versions version")
+			continue
+		}
+
+		cfg.Binary = append(cfg.Binary, &Binary{
+			Name:    name,
+			Version: version,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		zlog.Error().Err(err).Msg("failed to read .tool-versions file")
+		return nil, err
+	}
+
+	return &cfg, nil
+}
@@ -0,0 +1,55 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"github.com/rs/zerolog"
+	"strings"
+	"testing"
+)
+
+func TestParseToolVersions(t *testing.T) {
+	zlog := zerolog.Nop()
+	input := `# pinned tools
+golang 1.21.3
+nodejs 18.16.0
+poetry 1.3.2 1.2.0 # the first version wins
+protobuf system
+
+erlang 26.0.2
+`
+
+	cfg, err := ParseToolVersions(strings.NewReader(input), &zlog)
+	if err != nil {
+		t.Fatalf("ParseToolVersions returned error: %v", err)
+	}
+
+	expected := []Binary{
+		{Name: "go", Version: "1.21.3"},
+		{Name: "poetry", Version: "1.3.2"},
+		{Name: "erl", Version: "26.0.2"},
+	}
+	if len(cfg.Binary) != len(expected) {
+		t.Fatalf("ParseToolVersions returned %d binaries, want %d", len(cfg.Binary), len(expected))
+	}
+	for i, binary := range cfg.Binary {
+		if binary.Name != expected[i].Name || binary.Version != expected[i].Version {
+			t.Errorf("binary %d = %+v, want %+v", i, *binary, expected[i])
+		}
+	}
+}
@@ -0,0 +1,175 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"github.com/asimihsan/version-enforcer/identifier"
+	"net/url"
+	"os"
+)
+
+var (
+	ErrPathNotFound     = errors.New("path does not exist")
+	ErrConflictingEntry = errors.New("binary is configured more than once with different requirements")
+	ErrAssertEqual      = errors.New("assert_equal must name at least two configured binaries")
+)
+
+// Issue is a problem with one binary in a config.
+type Issue struct {
+	Binary string
+	Err    error
+}
+
+func (i Issue) Error() string {
+	return fmt.Sprintf("binary %q: %v", i.Binary, i.Err)
+}
+
+func (i Issue) Unwrap() error {
+	return i.Err
+}
+
+// Validate checks that every binary in cfg names a supported program or says how else to find its
+// version, and has a requirement that its comparator can parse, and that assert_equal names
+// configured binaries. It returns every issue it finds, in config order, and never runs a program.
+func Validate(cfg *Config) []Issue {
+	var issues []Issue
+	for _, binary := range cfg.Binary {
+		for _, err := range validateBinary(binary) {
+			issues = append(issues, Issue{Binary: binary.Name, Err: err})
+		}
+	}
+
+	if len(cfg.AssertEqual) == 1 {
+		issues = append(issues, Issue{Binary: cfg.AssertEqual[0], Err: ErrAssertEqual})
+	}
+	for _, name := range cfg.AssertEqual {
+		if !cfg.configures(name) {
+			issues = append(issues, Issue{Binary: name, Err: fmt.Errorf("%w: %s is not configured", ErrAssertEqual, name)})
+		}
+	}
+	return issues
+}
+
+// configures returns true if the config has a binary called name.
+func (c *Config) configures(name string) bool {
+	for _, binary := range c.Binary {
+		if binary.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateBinary returns every problem with a single binary.
+func validateBinary(binary *Binary) []error {
+	// A binary that must be absent is never run, so the program need not be supported.
+	if binary.Absent {
+		if binary.Version != "" || len(binary.Versions) > 0 || binary.MinVersion != "" || binary.MaxVersion != "" {
+			return []error{ErrAbsentWithVersion}
+		}
+		return nil
+	}
+
+	var errs []error
+
+	// A probe script or environment variable gives the version of any program, so the program need
+	// not be supported.
+	switch {
+	case binary.VersionEnv != "":
+	case binary.Probe != "":
+	case binary.IsGlob() && binary.Program == "" && binary.VersionSource == "":
+		errs = append(errs, ErrGlobRequiresProgram)
+	case binary.VersionSource == "":
+		if _, err := identifier.GetProgram(binary.ProgramName()); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s", err, binary.ProgramName()))
+		}
+	case binary.VersionSource == VersionSourceGoVersionM:
+	default:
+		errs = append(errs, fmt.Errorf("%w %q", ErrUnknownVersionSource, binary.VersionSource))
+	}
+
+	switch binary.OnNoMatch {
+	case "", OnNoMatchError, OnNoMatchSkip:
+	default:
+		errs = append(errs, fmt.Errorf("%w %q", ErrUnknownOnNoMatch, binary.OnNoMatch))
+	}
+
+	switch binary.OnOutdated {
+	case "", OnOutdatedWarn, OnOutdatedError:
+	default:
+		errs = append(errs, fmt.Errorf("%w %q", ErrUnknownOnOutdated, binary.OnOutdated))
+	}
+	if binary.LatestSource != "" {
+		if u, err := url.Parse(binary.LatestSource); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, fmt.Errorf("%w: %q", ErrInvalidLatestSource, binary.LatestSource))
+		}
+	}
+
+	if binary.VersionArgs != nil && len(binary.VersionArgs) == 0 {
+		errs = append(errs, ErrEmptyVersionArgs)
+	}
+
+	if err := binary.checkRequirement(); err != nil {
+		errs = append(errs, err)
+	}
+
+	for _, excluded := range binary.Exclude {
+		if _, err := identifier.ParseVersion(excluded); err != nil {
+			errs = append(errs, fmt.Errorf("exclude %q: %w", excluded, err))
+		}
+	}
+
+	return errs
+}
+
+// checkConflicts returns an issue for each binary that is configured again with the same path but a
+// different requirement. This is not part of Validate, because enforcing such a config checks each
+// entry on its own.
+func checkConflicts(cfg *Config) []Issue {
+	var issues []Issue
+	seen := make(map[string]*Binary)
+	for _, binary := range cfg.Binary {
+		key := binary.Name + "\x00" + binary.Path
+		if other, ok := seen[key]; ok && other.RequirementString() != binary.RequirementString() {
+			err := fmt.Errorf("%w: %s and %s", ErrConflictingEntry, other.RequirementString(), binary.RequirementString())
+			issues = append(issues, Issue{Binary: binary.Name, Err: err})
+		}
+		seen[key] = binary
+	}
+	return issues
+}
+
+// checkPaths returns an issue for each binary whose path or working directory does not exist. This
+// is not part of Validate, because a missing path is reported as a missing binary when enforcing the
+// config.
+func checkPaths(cfg *Config) []Issue {
+	var issues []Issue
+	for _, binary := range cfg.Binary {
+		for _, path := range []string{binary.Path, binary.WorkingDir} {
+			if path == "" {
+				continue
+			}
+			if _, err := os.Stat(path); err != nil {
+				issues = append(issues, Issue{Binary: binary.Name, Err: fmt.Errorf("%w: %s", ErrPathNotFound, path)})
+			}
+		}
+	}
+	return issues
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"errors"
+	"github.com/rs/zerolog"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifyConfig(t *testing.T) {
+	zlog := zerolog.Nop()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "version-enforcer.hcl")
+
+	writeFile(t, path, `
+binary "go" {
+  version = "~1.21"
+}
+
+binary "not-a-program" {
+  version = "1.0"
+}
+
+binary "git" {
+  version     = "~2"
+  exclude     = ["latest"]
+  on_no_match = "ignore"
+}
+
+binary "go" {
+  version = "~1.22"
+}
+
+binary "protoc" {
+  version = "~3"
+  path    = "/does/not/exist/protoc"
+}
+
+binary "probed" {
+  version = "1.0"
+  probe   = "missing.sh"
+}
+`)
+	issues, err := VerifyConfig(path, &zlog)
+	if err != nil {
+		t.Fatalf("VerifyConfig returned error: %v", err)
+	}
+
+	want := []struct {
+		binary string
+		err    error
+	}{
+		{"probed", ErrProbeNotExecutable},
+		{"not-a-program", nil},
+		{"git", ErrUnknownOnNoMatch},
+		{"git", nil},
+		{"go", ErrConflictingEntry},
+		{"protoc", ErrPathNotFound},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("VerifyConfig returned %d issues, want %d: %v", len(issues), len(want), issues)
+	}
+	for i, w := range want {
+		if issues[i].Binary != w.binary || (w.err != nil && !errors.Is(issues[i], w.err)) {
+			t.Errorf("issue %d = %v, want binary %q with error %v", i, issues[i], w.binary, w.err)
+		}
+	}
+
+	// LoadConfig fails with the first issue, but does not check paths.
+	if _, err := LoadConfig(path, &zlog); !errors.Is(err, ErrProbeNotExecutable) {
+		t.Errorf("LoadConfig error = %v, want %v", err, ErrProbeNotExecutable)
+	}
+
+	// LoadConfig accepts a binary configured twice with different requirements, and enforces both.
+	writeFile(t, path, "binary \"go\" {\n  version = \"~1.21\"\n}\n\nbinary \"go\" {\n  version = \"~1.22\"\n}\n")
+	if cfg, err := LoadConfig(path, &zlog); err != nil || len(cfg.Binary) != 2 {
+		t.Errorf("LoadConfig = %+v, %v, want both go binaries", cfg, err)
+	}
+	if issues, err := VerifyConfig(path, &zlog); err != nil || len(issues) != 1 || !errors.Is(issues[0], ErrConflictingEntry) {
+		t.Errorf("VerifyConfig = %v, %v, want %v", issues, err, ErrConflictingEntry)
+	}
+
+	writeFile(t, path, "binary \"protoc\" {\n  version = \"~3\"\n  path = \"/does/not/exist/protoc\"\n}\n")
+	if _, err := LoadConfig(path, &zlog); err != nil {
+		t.Errorf("LoadConfig returned error: %v", err)
+	}
+	if issues, err := VerifyConfig(path, &zlog); err != nil || len(issues) != 1 {
+		t.Errorf("VerifyConfig = %v, %v, want one issue", issues, err)
+	}
+}
+
+func TestLoadConfigAssertEqual(t *testing.T) {
+	zlog := zerolog.Nop()
+	path := filepath.Join(t.TempDir(), "version-enforcer.hcl")
+
+	binaries := "binary \"cmake\" {\n  version = \"~3.27\"\n}\n\nbinary \"ctest\" {\n  version = \"~3.27\"\n}\n"
+	writeFile(t, path, "assert_equal = [\"cmake\", \"ctest\"]\n\n"+binaries)
+	cfg, err := LoadConfig(path, &zlog)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+	if len(cfg.AssertEqual) != 2 || cfg.AssertEqual[1] != "ctest" {
+		t.Errorf("AssertEqual = %v, want [cmake ctest]", cfg.AssertEqual)
+	}
+
+	writeFile(t, path, "assert_equal = [\"cmake\", \"cpack\"]\n\n"+binaries)
+	if _, err := LoadConfig(path, &zlog); !errors.Is(err, ErrAssertEqual) {
+		t.Errorf("LoadConfig error = %v, want %v", err, ErrAssertEqual)
+	}
+}
+
+func TestLoadConfigAbsent(t *testing.T) {
+	zlog := zerolog.Nop()
+	path := filepath.Join(t.TempDir(), "version-enforcer.hcl")
+
+	writeFile(t, path, "binary \"telnet\" {\n  absent = true\n}\n")
+	cfg, err := LoadConfig(path, &zlog)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+	if !cfg.Binary[0].Absent || cfg.Binary[0].RequirementString() != "absent" {
+		t.Errorf("binary = %+v, want telnet to be absent", cfg.Binary[0])
+	}
+
+	writeFile(t, path, "binary \"telnet\" {\n  absent  = true\n  version = \"1.0\"\n}\n")
+	if _, err := LoadConfig(path, &zlog); !errors.Is(err, ErrAbsentWithVersion) {
+		t.Errorf("LoadConfig error = %v, want %v", err, ErrAbsentWithVersion)
+	}
+}
+
+func TestLoadConfigWorkingDir(t *testing.T) {
+	zlog := zerolog.Nop()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "version-enforcer.hcl")
+
+	writeFile(t, path, "binary \"go\" {\n  version     = \"~1.21\"\n  working_dir = \"project\"\n}\n")
+	cfg, err := LoadConfig(path, &zlog)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+	if want := filepath.Join(dir, "project"); cfg.Binary[0].WorkingDir != want {
+		t.Errorf("WorkingDir = %q, want %q relative to the config", cfg.Binary[0].WorkingDir, want)
+	}
+
+	// The directory does not exist.
+	issues, err := VerifyConfig(path, &zlog)
+	if err != nil || len(issues) != 1 || !errors.Is(issues[0], ErrPathNotFound) {
+		t.Errorf("VerifyConfig = %v, %v, want %v", issues, err, ErrPathNotFound)
+	}
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// DefaultConfigPath is where `enforce init` writes a starter config by default.
+const DefaultConfigPath = "tool-enforcer.hcl"
+
+// SaveRequirements writes the name and version requirement of each binary in cfg to a config file
+// at path. An existing file is only replaced if overwrite is true.
+func SaveRequirements(path string, cfg *Config, overwrite bool) error {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !overwrite {
+		flags |= os.O_EXCL
+	}
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return err
+	}
+
+	if err := WriteRequirements(f, cfg); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// WriteRequirements writes the name and version requirement of each binary in cfg as HCL that
+// LoadConfig can read, e.g.
+//
+//	binary "git" {
+//	  version = "^2.39.1"
+//	}
+//
+// Other fields are not written.
+func WriteRequirements(w io.Writer, cfg *Config) error {
+	for i, binary := range cfg.Binary {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		requirement := fmt.Sprintf("version = %q", binary.Version)
+		if binary.Absent {
+			requirement = "absent = true"
+		}
+		if _, err := fmt.Fprintf(w, "binary %q {\n  %s\n}\n", binary.Name, requirement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package config
+
+import (
+	"errors"
+	"github.com/rs/zerolog"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveRequirements(t *testing.T) {
+	zlog := zerolog.Nop()
+	cfg := &Config{Binary: []*Binary{
+		{Name: "git", Version: "^2.39.1"},
+		{Name: "go", Version: "^1.21.3"},
+	}}
+
+	path := filepath.Join(t.TempDir(), DefaultConfigPath)
+	if err := SaveRequirements(path, cfg, false); err != nil {
+		t.Fatalf("SaveRequirements returned error: %v", err)
+	}
+
+	loaded, err := LoadConfig(path, &zlog)
+	if err != nil {
+		t.Fatalf("LoadConfig returned error: %v", err)
+	}
+	if len(loaded.Binary) != 2 || loaded.Binary[0].Name != "git" || loaded.Binary[1].Version != "^1.21.3" {
+		t.Errorf("LoadConfig = %+v, want %+v", loaded.Binary, cfg.Binary)
+	}
+
+	if err := SaveRequirements(path, cfg, false); !errors.Is(err, os.ErrExist) {
+		t.Errorf("SaveRequirements error = %v, want %v", err, os.ErrExist)
+	}
+	if err := SaveRequirements(path, cfg, true); err != nil {
+		t.Errorf("SaveRequirements with overwrite returned error: %v", err)
+	}
+}
@@ -0,0 +1,375 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package identifier
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Names of the built-in comparators.
+const (
+	ComparatorSemver  = "semver"
+	ComparatorDate    = "date"
+	ComparatorLexical = "lexical"
+)
+
+var (
+	ErrUnknownComparator      = errors.New("unknown comparator")
+	ErrUnsupportedRequirement = errors.New("requirement is not supported by the comparator")
+	ErrInvalidDateVersion     = errors.New("invalid date version")
+
+	// dateVersionRegex matches a year, an optional month and day separated by "." or "-", and
+	// optional trailing letters, e.g. "2023", "2023.05", "2023-05-01", or "2023c".
+	dateVersionRegex = regexp.MustCompile(`^([0-9]{4})(?:[.-]([0-9]{1,2}))?(?:[.-]([0-9]{1,2}))?([a-z]*)$`)
+)
+
+// Comparator compares two versions of a program. Compare returns a negative number if a is older
+// than b, zero if they are the same version, and a positive number if a is newer than b.
+type Comparator interface {
+	Compare(a, b string) (int, error)
+}
+
+// SemverComparator compares numeric versions such as "1.2.3". It is the default, and the only
+// comparator that supports "^", "~", and "~>" requirements.
+type SemverComparator struct{}
+
+func (SemverComparator) Compare(a, b string) (int, error) {
+	va, err := ParseVersion(a)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q: %v", ErrInvalidVersion, a, err)
+	}
+	vb, err := ParseVersion(b)
+	if err != nil {
+		return 0, fmt.Errorf("%w %q: %v", ErrInvalidVersion, b, err)
+	}
+	return CompareSemverVersions(*va, *vb), nil
+}
+
+// DateComparator compares date-based versions such as "2023.05", "2023-05-01", and tzdata's
+// "2023c". The year, month, and day are compared numerically, with a missing month or day treated
+// as zero, and then trailing letters are compared, so "2023c" is newer than "2023a".
+type DateComparator struct{}
+
+func (DateComparator) Compare(a, b string) (int, error) {
+	da, err := parseDateVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	db, err := parseDateVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	for i := range da.parts {
+		if da.parts[i] != db.parts[i] {
+			if da.parts[i] < db.parts[i] {
+				return -1, nil
+			}
+			return 1, nil
+		}
+	}
+	if len(da.suffix) != len(db.suffix) {
+		if len(da.suffix) < len(db.suffix) {
+			return -1, nil
+		}
+		return 1, nil
+	}
+	return strings.Compare(da.suffix, db.suffix), nil
+}
+
+type dateVersion struct {
+	parts  [3]int
+	suffix string
+}
+
+func parseDateVersion(s string) (dateVersion, error) {
+	matches := dateVersionRegex.FindStringSubmatch(strings.TrimSpace(s))
+	if matches == nil {
+		return dateVersion{}, fmt.Errorf("%w %q", ErrInvalidDateVersion, s)
+	}
+	var d dateVersion
+	for i, part := range matches[1:4] {
+		if part == "" {
+			continue
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return dateVersion{}, fmt.Errorf("%w %q: %v", ErrInvalidDateVersion, s, err)
+		}
+		d.parts[i] = n
+	}
+	d.suffix = matches[4]
+	return d, nil
+}
+
+// LexicalComparator compares versions as strings. It is a last resort for versions that have no
+// other order, and only works if every version has the same length, e.g. "20230501".
+type LexicalComparator struct{}
+
+func (LexicalComparator) Compare(a, b string) (int, error) {
+	return strings.Compare(strings.TrimSpace(a), strings.TrimSpace(b)), nil
+}
+
+// ComparatorByName returns the built-in comparator with the given name. An empty name is the
+// semver comparator.
+func ComparatorByName(name string) (Comparator, error) {
+	switch name {
+	case "", ComparatorSemver:
+		return SemverComparator{}, nil
+	case ComparatorDate:
+		return DateComparator{}, nil
+	case ComparatorLexical:
+		return LexicalComparator{}, nil
+	default:
+		return nil, fmt.Errorf("%w %q", ErrUnknownComparator, name)
+	}
+}
+
+// SatisfiesWith is like SatisfiesE, but compares versions with c. Unless c is a SemverComparator,
+// the requirement must be a version or a single comparison such as ">= 2023c".
+func SatisfiesWith(version string, requirement string, c Comparator) (bool, error) {
+	if _, ok := c.(SemverComparator); ok || c == nil {
+		return SatisfiesE(version, requirement)
+	}
+	if err := CheckRequirement(requirement, c); err != nil {
+		return false, err
+	}
+
+	alternatives, err := splitAlternatives(requirement)
+	if err != nil {
+		return false, err
+	}
+	for _, alternative := range alternatives {
+		satisfied, err := satisfiesComparisons(version, splitClauses(alternative), c)
+		if err != nil || satisfied {
+			return satisfied, err
+		}
+	}
+	return false, nil
+}
+
+// satisfiesComparisons returns true if version satisfies every one of clauses, compared with c.
+func satisfiesComparisons(version string, clauses []string, c Comparator) (bool, error) {
+	for _, clause := range clauses {
+		satisfied, err := satisfiesComparison(version, clause, c)
+		if err != nil || !satisfied {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// ExplainWith describes how each clause of each alternative of requirement was parsed and whether
+// version satisfies it when compared with c, one clause per line. Semver clauses are explained by
+// ExplainRequirement.
+func ExplainWith(version string, requirement string, c Comparator) (string, error) {
+	_, isSemver := c.(SemverComparator)
+	isSemver = isSemver || c == nil
+	if !isSemver {
+		if err := CheckRequirement(requirement, c); err != nil {
+			return "", err
+		}
+	}
+
+	alternatives, err := splitAlternatives(requirement)
+	if err != nil {
+		return "", err
+	}
+	lines := make([]string, 0, len(alternatives))
+	for _, alternative := range alternatives {
+		for _, clause := range splitClauses(alternative) {
+			if isSemver {
+				req, err := NewRequirement(clause)
+				if err != nil {
+					return "", err
+				}
+				lines = append(lines, ExplainRequirement(version, *req))
+				continue
+			}
+
+			outcome := "satisfied"
+			satisfied, err := satisfiesComparison(version, clause, c)
+			switch {
+			case err != nil:
+				outcome = fmt.Sprintf("not satisfied (%v)", err)
+			case !satisfied:
+				outcome = "not satisfied"
+			}
+			lines = append(lines, fmt.Sprintf("requirement %s compared as %s versions; found %s → %s", clause, comparatorName(c), version, outcome))
+		}
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// comparatorName returns the name of a built-in comparator, or its type for any other comparator.
+func comparatorName(c Comparator) string {
+	switch c.(type) {
+	case SemverComparator:
+		return ComparatorSemver
+	case DateComparator:
+		return ComparatorDate
+	case LexicalComparator:
+		return ComparatorLexical
+	default:
+		return fmt.Sprintf("%T", c)
+	}
+}
+
+// satisfiesComparison returns true if version satisfies a requirement that is a version or a
+// single comparison, compared with c.
+func satisfiesComparison(version string, requirement string, c Comparator) (bool, error) {
+	requirementType, required, err := splitComparison(requirement)
+	if err != nil {
+		return false, err
+	}
+	cmp, err := c.Compare(version, required)
+	if err != nil {
+		return false, err
+	}
+
+	switch requirementType {
+	case SingleConditionGreaterThan:
+		return cmp > 0, nil
+	case SingleConditionLessThan:
+		return cmp < 0, nil
+	case SingleConditionGreaterThanOrEqual:
+		return cmp >= 0, nil
+	case SingleConditionLessThanOrEqual:
+		return cmp <= 0, nil
+	default:
+		return cmp == 0, nil
+	}
+}
+
+// CompareToRequirementWith is like CompareToRequirement, but compares versions with c. Unless c is
+// a SemverComparator, each clause of the requirement must be a version or a single comparison. If
+// an alternative has several clauses, its result is that of the first clause that v is outside. If
+// the requirement has several alternatives separated by "||", v is only older or newer if it is
+// older or newer than every alternative, e.g. 1.21.0 is neither for "=1.20.3 || =1.21.5".
+func CompareToRequirementWith(version string, requirement string, c Comparator) (int, error) {
+	alternatives, err := splitAlternatives(requirement)
+	if err != nil {
+		return 0, err
+	}
+	result := 0
+	for i, alternative := range alternatives {
+		cmp, err := compareToAlternative(version, alternative, c)
+		if err != nil {
+			return 0, err
+		}
+		if cmp == 0 || (i > 0 && cmp != result) {
+			return 0, nil
+		}
+		result = cmp
+	}
+	return result, nil
+}
+
+// compareToAlternative is like CompareToRequirementWith, but for a single alternative.
+func compareToAlternative(version string, alternative string, c Comparator) (int, error) {
+	for _, clause := range splitClauses(alternative) {
+		cmp, err := compareToClause(version, clause, c)
+		if err != nil || cmp != 0 {
+			return cmp, err
+		}
+	}
+	return 0, nil
+}
+
+// compareToClause is like CompareToRequirementWith, but for a single clause.
+func compareToClause(version string, requirement string, c Comparator) (int, error) {
+	if _, ok := c.(SemverComparator); ok || c == nil {
+		req, err := NewRequirement(requirement)
+		if err != nil {
+			return 0, err
+		}
+		v, err := ParseVersion(version)
+		if err != nil {
+			return 0, fmt.Errorf("%w %q: %v", ErrInvalidVersion, version, err)
+		}
+		return CompareToRequirement(*v, *req), nil
+	}
+
+	requirementType, required, err := splitComparison(requirement)
+	if err != nil {
+		return 0, err
+	}
+	cmp, err := c.Compare(version, required)
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case requirementType == SingleConditionGreaterThan && cmp <= 0,
+		requirementType == SingleConditionGreaterThanOrEqual && cmp < 0,
+		(requirementType == Exact || requirementType == SingleConditionEqual) && cmp < 0:
+		return -1, nil
+	case requirementType == SingleConditionLessThan && cmp >= 0,
+		requirementType == SingleConditionLessThanOrEqual && cmp > 0,
+		(requirementType == Exact || requirementType == SingleConditionEqual) && cmp > 0:
+		return 1, nil
+	default:
+		return 0, nil
+	}
+}
+
+// CheckRequirement returns an error if requirement cannot be used with c.
+func CheckRequirement(requirement string, c Comparator) error {
+	alternatives, err := splitAlternatives(requirement)
+	if err != nil {
+		return err
+	}
+	for _, alternative := range alternatives {
+		for _, clause := range splitClauses(alternative) {
+			if _, ok := c.(SemverComparator); ok || c == nil {
+				if _, err := NewRequirement(clause); err != nil {
+					return err
+				}
+				continue
+			}
+			_, required, err := splitComparison(clause)
+			if err != nil {
+				return err
+			}
+			if _, err := c.Compare(required, required); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// splitComparison splits a requirement that is a version or a single comparison into its type and
+// version.
+func splitComparison(requirement string) (RequirementType, string, error) {
+	s := strings.TrimSpace(requirement)
+	if strings.HasPrefix(s, "^") || strings.HasPrefix(s, "~") {
+		return 0, "", fmt.Errorf("%w: %q", ErrUnsupportedRequirement, requirement)
+	}
+	matches := operatorRegex.FindStringSubmatch(s)
+	if len(matches) != 3 {
+		return Exact, s, nil
+	}
+	requirementType, ok := conditionOperatorToType[matches[1]]
+	if !ok {
+		return 0, "", fmt.Errorf("%w %q in %q", ErrInvalidOperator, matches[1], requirement)
+	}
+	return requirementType, strings.TrimSpace(matches[2]), nil
+}
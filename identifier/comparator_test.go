@@ -0,0 +1,85 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package identifier
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDateComparator(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected int
+	}{
+		{"2023c", "2023a", 1},
+		{"2023a", "2023c", -1},
+		{"2023c", "2023c", 0},
+		{"2023a", "2022z", 1},
+		{"2023.05", "2023.4", 1},
+		{"2023.05", "2023-05", 0},
+		{"2023-05-01", "2023.05", 1},
+		{"2023", "2023a", -1},
+	}
+	for _, test := range tests {
+		actual, err := DateComparator{}.Compare(test.a, test.b)
+		if err != nil {
+			t.Errorf("Compare(%s, %s) returned error: %v", test.a, test.b, err)
+			continue
+		}
+		if actual != test.expected {
+			t.Errorf("Compare(%s, %s) = %d, want %d", test.a, test.b, actual, test.expected)
+		}
+	}
+
+	if _, err := (DateComparator{}).Compare("1.2.3", "2023a"); !errors.Is(err, ErrInvalidDateVersion) {
+		t.Errorf("Compare(1.2.3, 2023a) error = %v, want %v", err, ErrInvalidDateVersion)
+	}
+}
+
+func TestSatisfiesWith(t *testing.T) {
+	tests := []struct {
+		version     string
+		requirement string
+		expected    bool
+	}{
+		{"2023c", "2023c", true},
+		{"2023c", ">= 2023b", true},
+		{"2023a", ">= 2023b", false},
+		{"2024a", "< 2024", false},
+		{"2023.05", "<= 2023.05", true},
+	}
+	for _, test := range tests {
+		actual, err := SatisfiesWith(test.version, test.requirement, DateComparator{})
+		if err != nil {
+			t.Errorf("SatisfiesWith(%s, %s) returned error: %v", test.version, test.requirement, err)
+			continue
+		}
+		if actual != test.expected {
+			t.Errorf("SatisfiesWith(%s, %s) = %t, want %t", test.version, test.requirement, actual, test.expected)
+		}
+	}
+
+	if _, err := SatisfiesWith("2023c", "~2023", DateComparator{}); !errors.Is(err, ErrUnsupportedRequirement) {
+		t.Errorf("SatisfiesWith(2023c, ~2023) error = %v, want %v", err, ErrUnsupportedRequirement)
+	}
+	if satisfied, err := SatisfiesWith("1.9.0", "~> 1.2", SemverComparator{}); err != nil || !satisfied {
+		t.Errorf("SatisfiesWith(1.9.0, ~> 1.2, semver) = %t, %v, want true", satisfied, err)
+	}
+}
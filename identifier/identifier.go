@@ -18,239 +18,491 @@
 package identifier
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"github.com/asimihsan/version-enforcer/command"
 	"github.com/rs/zerolog"
-	"regexp"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
 	"strings"
 )
 
-// enum for Programs that we can identify
-type Program int
+// SemverVersion is a string, should be lexicographically sortable (e.g. semver).
+type Version string
+
+// Stream is an output stream of a program.
+type Stream string
 
 const (
-	Make Program = iota
-	Git
-	Bash
-	Go
-	Protobuf
-	PkgConfig
-	Poetry
+	StreamStdout Stream = "stdout"
+	StreamStderr Stream = "stderr"
 )
 
-// SemverVersion is a string, should be lexicographically sortable (e.g. semver).
-type Version string
+// Identification is what could be identified about an installed program.
+type Identification struct {
+	Version Version
 
-var identifierMap = map[Program]func(string, *zerolog.Logger) (Version, error){
-	Make:      identifyMake,
-	Git:       identifyGit,
-	Bash:      identifyBash,
-	Go:        identifyGo,
-	Protobuf:  identifyProtobuf,
-	PkgConfig: identifyPkgConfig,
-	Poetry:    identifyPoetry,
-}
+	// Commit is the commit that the program was built from, if its version output includes one.
+	Commit string
 
-var programNameToProgramMap = map[string]Program{
-	"make":       Make,
-	"git":        Git,
-	"bash":       Bash,
-	"go":         Go,
-	"protoc":     Protobuf,
-	"pkg-config": PkgConfig,
-	"poetry":     Poetry,
-}
+	// Raw is the output that the version was found in, with surrounding whitespace trimmed, and
+	// Stream is where the program wrote it.
+	Raw    string
+	Stream Stream
 
-var programToProgramNameMap = map[Program]string{
-	Make:      "make",
-	Git:       "git",
-	Bash:      "bash",
-	Go:        "go",
-	Protobuf:  "protoc",
-	PkgConfig: "pkg-config",
-	Poetry:    "poetry",
+	// Path is the executable that was run to print the version, as found in $PATH or configured,
+	// e.g. to show which of several installed copies of a program was checked. It is the invoker
+	// if IdentifyOptions.Invoker is set.
+	Path string
+
+	// Note is informational, e.g. explaining why an old version may have been found. It does not
+	// affect whether the version satisfies a requirement.
+	Note string
+
+	// Method describes how the version was found, e.g. "built-in `go version`" or "probe script
+	// ./probe.sh", to help debug configs that override how a program is run.
+	Method string
 }
 
+// defaultIdentifier is used by the package functions. It only has the built-in programs.
+var defaultIdentifier = NewIdentifier()
+
 // GetProgram returns the Program for the given name, if found.
 func GetProgram(programName string) (*Program, error) {
-	p, ok := programNameToProgramMap[programName]
-	if !ok {
-		return nil, errors.New("program not found")
-	}
-	return &p, nil
+	return defaultIdentifier.GetProgram(programName)
+}
+
+// Programs returns every supported Program, sorted by name.
+func Programs() []Program {
+	return defaultIdentifier.Programs()
 }
 
 // GetProgramName returns the name of the given Program.
 func GetProgramName(p Program) string {
-	return programToProgramNameMap[p]
+	return defaultIdentifier.GetProgramName(p)
 }
 
+// GetComparator returns the comparator for versions of the given Program, which is a
+// SemverComparator unless the program uses another versioning scheme.
+func GetComparator(p Program) Comparator {
+	return defaultIdentifier.GetComparator(p)
+}
+
+// GetInstallHint returns a built-in hint for how to install the given Program, or an empty string
+// if there is none.
+func GetInstallHint(p Program) string {
+	return defaultIdentifier.GetInstallHint(p)
+}
+
+// SetRunner sets the Runner of the Identifier used by the package functions, e.g. to retry version
+// commands. It must not be called while programs are being identified.
+func SetRunner(runner Runner) {
+	defaultIdentifier.Runner = runner
+}
+
+// runCommand runs version commands for Identifiers without a Runner, and lookPath resolves
+// executables. Tests replace them to avoid depending on installed programs.
+var (
+	runCommand = command.RunCommandOutput
+	lookPath   = exec.LookPath
+)
+
 var (
 	ErrProgramNotSupported = errors.New("program not supported")
+	ErrProgramNotInstalled = errors.New("program not installed")
+	ErrOutsidePathPrefix   = errors.New("program is not under the required path prefix")
+	ErrEmptyVersionOutput  = errors.New("version output is empty")
+	ErrVersionEnvUnset     = errors.New("version environment variable is not set")
 )
 
+// IdentifyOptions overrides how a program is run to print its version.
+type IdentifyOptions struct {
+	// Path is the executable to run instead of looking up the program's name in $PATH.
+	Path string
+
+	// Args are run instead of the program's built-in arguments, if not nil.
+	Args []string
+
+	// PathPrefix, if set, is a directory that the resolved executable must be under, e.g. to make
+	// sure a program comes from /usr/local/bin rather than a shim earlier in $PATH.
+	PathPrefix string
+
+	// Invoker, if set, is a command that the program's name and arguments are appended to, such as
+	// ["asdf", "exec"] or ["mise", "exec", "--"], so that a version manager runs the version of
+	// the program pinned by the project. Only the invoker is looked up in $PATH, and PathPrefix
+	// then applies to it. IdentifyGoModule does not use it.
+	Invoker []string
+}
+
 // Identify returns the version of the program p, or an error if the program is not supported.
-func Identify(p Program, zlog *zerolog.Logger) (Version, error) {
-	identifier, ok := identifierMap[p]
-	if !ok {
-		zlog.Debug().Msg("program not supported")
-		return "", ErrProgramNotSupported
-	}
-	versionOutput, err := getProgramVersionOutput(p, zlog)
+func Identify(p Program, zlog *zerolog.Logger) (Identification, error) {
+	return defaultIdentifier.Identify(p, zlog)
+}
+
+// IdentifyContext is like Identify, but kills the program and returns ctx's error if ctx is done
+// before the program exits.
+func IdentifyContext(ctx context.Context, p Program, zlog *zerolog.Logger) (Identification, error) {
+	return defaultIdentifier.IdentifyContext(ctx, p, zlog)
+}
+
+// IdentifyWithArgs is like Identify, but runs the program with versionArgs instead of its built-in
+// arguments, if versionArgs is not nil. The program's built-in parser is still used.
+func IdentifyWithArgs(p Program, versionArgs []string, zlog *zerolog.Logger) (Identification, error) {
+	return defaultIdentifier.IdentifyWithArgs(p, versionArgs, zlog)
+}
+
+// IdentifyWithOptions is like IdentifyContext, but runs the program as described by opts.
+func IdentifyWithOptions(ctx context.Context, p Program, opts IdentifyOptions, zlog *zerolog.Logger) (Identification, error) {
+	return defaultIdentifier.IdentifyWithOptions(ctx, p, opts, zlog)
+}
+
+// identifySpec runs the program described by spec and opts and identifies its version.
+func (id *Identifier) identifySpec(ctx context.Context, spec programSpec, opts IdentifyOptions, zlog *zerolog.Logger) (Identification, error) {
+	versionOutput, path, err := id.getProgramVersionOutput(ctx, spec, opts, zlog)
 	if err != nil {
 		zlog.Debug().Err(err).Msg("failed to get program version output")
-		return "", err
+		return Identification{}, err
+	}
+
+	identification, err := identifyStreams(spec, versionOutput, zlog)
+	if err != nil {
+		// Some shell wrappers print an error but exit 0, so an error that looks like the program is
+		// missing is more helpful than the output not matching.
+		if line := id.notInstalledLine(versionOutput); line != "" {
+			zlog.Debug().Str("line", line).Msg("output says program is not installed")
+			return Identification{}, fmt.Errorf("%w: %s printed %q", ErrProgramNotInstalled, spec.name, line)
+		}
+		return Identification{}, err
+	}
+	identification.Path = path
+	name, args := versionCommand(spec, opts)
+	method := "built-in"
+	if opts.Args != nil {
+		method = "custom arguments"
 	}
-	return identifier(versionOutput, zlog)
+	identification.Method = fmt.Sprintf("%s `%s`", method, strings.Join(append([]string{name}, args...), " "))
+	if spec.note != nil {
+		identification.Note = spec.note(identification.Version)
+	}
+	return identification, nil
 }
 
-// s is a single line, e.g.
-//
-// git version 2.39.1
-func identifyGit(s string, zlog *zerolog.Logger) (Version, error) {
-	word, err := getLastWordOnFirstLine(s)
+// identifyStreams identifies the version in stdout, falling back to stderr.
+func identifyStreams(spec programSpec, versionOutput command.Output, zlog *zerolog.Logger) (Identification, error) {
+	// Most programs print their version to stdout, but some, such as erl, print it to stderr.
+	identification, err := identifyOutput(spec, versionOutput.Stdout, zlog)
+	if err == nil {
+		identification.Stream = StreamStdout
+		return identification, nil
+	}
+	if strings.TrimSpace(versionOutput.Stderr) == "" {
+		return Identification{}, err
+	}
+	identification, err = identifyOutput(spec, versionOutput.Stderr, zlog)
 	if err != nil {
-		zlog.Debug().Err(err).Msg("failed to get last word on first line")
-		return "", err
+		return Identification{}, err
 	}
-	return Version(word), nil
+	identification.Stream = StreamStderr
+	return identification, nil
 }
 
-// On the first line, get the last whitespace-delimited element.
-//
-// Example s:
-//
-// GNU Make 4.4
-// Built for aarch64-apple-darwin21.6.0
-// Copyright (C) 1988-2022 Free Software Foundation, Inc.
-// License GPLv3+: GNU GPL version 3 or later <https://gnu.org/licenses/gpl.html>
-// This is free software: you are free to change and redistribute it.
-// There is NO WARRANTY, to the extent permitted by law.
-func identifyMake(s string, zlog *zerolog.Logger) (Version, error) {
-	word, err := getLastWordOnFirstLine(s)
+// notInstalledLine returns the first line of output that contains one of the Identifier's
+// NotInstalledPhrases, ignoring case, or an empty string if there is none.
+func (id *Identifier) notInstalledLine(output command.Output) string {
+	for _, s := range []string{output.Stdout, output.Stderr} {
+		for _, line := range strings.Split(s, "\n") {
+			lower := strings.ToLower(line)
+			for _, phrase := range id.NotInstalledPhrases {
+				if strings.Contains(lower, strings.ToLower(phrase)) {
+					return strings.TrimSpace(line)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// identifyOutput finds the version, and the commit if the program reports one, in the output of
+// the program's version command using the program's regexes or parse function.
+func identifyOutput(spec programSpec, s string, zlog *zerolog.Logger) (Identification, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return Identification{}, fmt.Errorf("%w: %s", ErrEmptyVersionOutput, spec.name)
+	}
+	if spec.parse != nil {
+		version, err := spec.parse(s)
+		if err != nil {
+			zlog.Debug().Str("name", spec.name).Err(err).Msg("no version in output")
+			return Identification{}, err
+		}
+		return Identification{Version: version, Raw: s}, nil
+	}
+	searched := s
+	if !spec.allLines {
+		searched = strings.TrimSpace(strings.SplitN(s, "\n", 2)[0])
+	}
+	matches := spec.regex.FindStringSubmatch(searched)
+	if spec.fallbackRegex != nil && (len(matches) < 2 || !isVersion(matches[1])) {
+		zlog.Debug().Str("name", spec.name).Str("regex", spec.fallbackRegex.String()).Msg("trying fallback regex")
+		matches = spec.fallbackRegex.FindStringSubmatch(searched)
+	}
+	if len(matches) < 2 || matches[1] == "" {
+		zlog.Debug().Str("name", spec.name).Str("regex", spec.regex.String()).Msg("no version in output")
+		return Identification{}, errors.New("no matches")
+	}
+	identification := Identification{Version: Version(matches[1]), Raw: s}
+
+	if spec.commitRegex != nil {
+		if matches := spec.commitRegex.FindStringSubmatch(s); len(matches) >= 2 {
+			identification.Commit = matches[1]
+		}
+	}
+	return identification, nil
+}
+
+// isVersion returns true if s parses as a version.
+func isVersion(s string) bool {
+	_, err := ParseVersion(s)
+	return err == nil
+}
+
+// IdentifyGoModule returns the main module version embedded in a binary built by `go install`,
+// using `go version -m`. This works for binaries that have no flag to print their version. The
+// Path and PathPrefix options are used as for IdentifyWithOptions, and Args is ignored.
+func IdentifyGoModule(ctx context.Context, name string, opts IdentifyOptions, zlog *zerolog.Logger) (Identification, error) {
+	return defaultIdentifier.IdentifyGoModule(ctx, name, opts, zlog)
+}
+
+// IdentifyGoModule is like the package function IdentifyGoModule, but runs go with the
+// Identifier's Runner.
+func (id *Identifier) IdentifyGoModule(ctx context.Context, name string, opts IdentifyOptions, zlog *zerolog.Logger) (Identification, error) {
+	if opts.Path != "" {
+		name = opts.Path
+	}
+	path, err := resolvePath(name, opts.PathPrefix, zlog)
+	if err != nil {
+		return Identification{}, err
+	}
+
+	output, err := id.run(ctx, "go", "version", "-m", path)
 	if err != nil {
-		zlog.Debug().Err(err).Msg("failed to get last word on first line")
-		return "", err
+		zlog.Debug().Interface("output", output).Err(err).Msg("failed to run command")
+		return Identification{}, commandError(ctx, err)
 	}
-	return Version(word), nil
+	identification, err := identifyGoModule(output.Stdout, zlog)
+	if err != nil {
+		return Identification{}, err
+	}
+	identification.Stream = StreamStdout
+	identification.Path = path
+	identification.Method = "`go version -m " + path + "`"
+	return identification, nil
 }
 
-// identifyBash uses a regex on the first line to get the version number
+// identifyGoModule gets the version from the "mod" line, which describes the main module, and the
+// commit from the vcs.revision build setting if the binary was built with one.
 //
 // Example s:
 //
-// GNU bash, version 5.1.8(1)-release (aarch64-apple-darwin21.6.0)
-// Copyright (C) 2022 Free Software Foundation, Inc.
-// License GPLv3+: GNU GPL version 3 or later <http://gnu.org/licenses/gpl.html>
+// /Users/asim/go/bin/gopls: go1.21.0
 //
-// This is free software; you are free to change and redistribute it.
-// There is NO WARRANTY, to the extent permitted by law.
-func identifyBash(s string, zlog *zerolog.Logger) (Version, error) {
-	regex := regexp.MustCompile(`GNU bash, version ([0-9]+\.[0-9]+\.[0-9]+)`)
-	lines := strings.Split(s, "\n")
-	if len(lines) == 0 {
-		return "", errors.New("no lines in output")
-	}
-	matches := regex.FindStringSubmatch(lines[0])
-	if len(matches) != 2 {
-		return "", errors.New("no matches")
-	}
-	return Version(matches[1]), nil
+//	path	golang.org/x/tools/gopls
+//	mod	golang.org/x/tools/gopls	v0.13.2	h1:Pyvx6MKvatbX3zzZmdGiFRfQZl0ohPlt2dFBKqOvLUM=
+//	dep	github.com/BurntSushi/toml	v1.2.1	h1:9F2/+DoOYIOksmaJFPw1tGFy1eDnIJXg+UHjuD8lTak=
+//	build	vcs.revision=2f0c8b8f1cbd6eab9c5ba5e87d1fbc8ef7e1d7a4
+func identifyGoModule(s string, zlog *zerolog.Logger) (Identification, error) {
+	identification := Identification{Raw: s}
+	for _, line := range strings.Split(s, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "build" && strings.HasPrefix(fields[1], "vcs.revision=") {
+			identification.Commit = strings.TrimPrefix(fields[1], "vcs.revision=")
+		}
+		if len(fields) < 3 || fields[0] != "mod" {
+			continue
+		}
+		if fields[2] == "(devel)" {
+			return Identification{}, errors.New("binary was not built from a module version")
+		}
+		identification.Version = Version(strings.TrimPrefix(fields[2], "v"))
+	}
+	if identification.Version == "" {
+		return Identification{}, errors.New("no main module in output")
+	}
+	return identification, nil
 }
 
-// identifyGo uses a regex on the first line to get the version number
-//
-// Example s:
-//
-// go version go1.17.5 darwin/arm64
-func identifyGo(s string, zlog *zerolog.Logger) (Version, error) {
-	regex := regexp.MustCompile(`go version go([0-9]+\.[0-9]+\.[0-9]+)`)
-	lines := strings.Split(s, "\n")
-	if len(lines) == 0 {
-		return "", errors.New("no lines in output")
-	}
-	matches := regex.FindStringSubmatch(lines[0])
-	if len(matches) != 2 {
-		return "", errors.New("no matches")
-	}
-	return Version(matches[1]), nil
+// IdentifyEnv returns the version in the environment variable named key, e.g. GO_VERSION=1.21.3,
+// without running the program. This allows versions to be checked where the program is not
+// installed.
+func IdentifyEnv(key string, zlog *zerolog.Logger) (Identification, error) {
+	value, ok := os.LookupEnv(key)
+	version := strings.TrimSpace(value)
+	if !ok || version == "" {
+		zlog.Debug().Str("key", key).Bool("set", ok).Msg("version environment variable is empty")
+		return Identification{}, fmt.Errorf("%w: $%s", ErrVersionEnvUnset, key)
+	}
+	return Identification{Version: Version(version), Raw: value, Method: "environment variable $" + key}, nil
 }
 
-// identifyProtobuf uses last word on first line
-//
-// Example s:
-//
-// libprotoc 3.19.1
-func identifyProtobuf(s string, zlog *zerolog.Logger) (Version, error) {
-	word, err := getLastWordOnFirstLine(s)
+// IdentifyProbe runs a probe script, which takes no arguments, and returns whatever it prints to
+// stdout as the version. This allows any program to be identified without supporting it here.
+func IdentifyProbe(ctx context.Context, path string, zlog *zerolog.Logger) (Identification, error) {
+	return defaultIdentifier.IdentifyProbe(ctx, path, zlog)
+}
+
+// IdentifyProbe is like the package function IdentifyProbe, but runs the probe with the
+// Identifier's Runner.
+func (id *Identifier) IdentifyProbe(ctx context.Context, path string, zlog *zerolog.Logger) (Identification, error) {
+	output, err := id.run(ctx, path)
 	if err != nil {
-		zlog.Debug().Err(err).Msg("failed to get last word on first line")
-		return "", err
+		zlog.Debug().Str("stdout", output.Stdout).Str("stderr", output.Stderr).Err(err).Msg("failed to run probe")
+		return Identification{}, commandError(ctx, err)
 	}
-	return Version(word), nil
+	version := strings.TrimSpace(output.Stdout)
+	if version == "" {
+		return Identification{}, fmt.Errorf("%w: probe %s", ErrEmptyVersionOutput, path)
+	}
+	return Identification{Version: Version(version), Raw: version, Stream: StreamStdout, Path: path, Method: "probe script " + path}, nil
 }
 
-// identifyPkgConfig uses last word on first line
-//
-// Example s:
-//
-// 0.29.2
-func identifyPkgConfig(s string, zlog *zerolog.Logger) (Version, error) {
-	word, err := getLastWordOnFirstLine(s)
+// resolvePath returns the absolute path of the executable name, or ErrProgramNotInstalled if it
+// cannot be found. If pathPrefix is set, the path must be under it, or ErrOutsidePathPrefix is
+// returned. Symlinks are not followed, because the prefix is about where the program was found.
+func resolvePath(name string, pathPrefix string, zlog *zerolog.Logger) (string, error) {
+	path, err := lookPath(name)
 	if err != nil {
-		zlog.Debug().Err(err).Msg("failed to get last word on first line")
-		return "", err
+		zlog.Debug().Err(err).Str("name", name).Msg("failed to find binary")
+		if errors.Is(err, exec.ErrNotFound) && !strings.ContainsRune(name, filepath.Separator) {
+			return "", fmt.Errorf("%w: %s not found in $PATH", ErrProgramNotInstalled, name)
+		}
+		return "", fmt.Errorf("%w: %s: %v", ErrProgramNotInstalled, name, err)
 	}
-	return Version(word), nil
+	zlog.Debug().Str("name", name).Str("path", path).Msg("resolved binary")
+
+	if pathPrefix != "" && !isUnderDir(path, pathPrefix) {
+		zlog.Debug().Str("path", path).Str("pathPrefix", pathPrefix).Msg("binary is outside path prefix")
+		return "", fmt.Errorf("%w: %s resolved to %s, which is not under %s", ErrOutsidePathPrefix, name, path, pathPrefix)
+	}
+	return path, nil
 }
 
-// identifyPoetry uses a regex on the first line to get the version number.
-//
-// Example s:
-//
-// Poetry (version 1.3.2)
-func identifyPoetry(s string, zlog *zerolog.Logger) (Version, error) {
-	regex := regexp.MustCompile(`Poetry \(version ([0-9]+\.[0-9]+\.[0-9]+)\)`)
-	lines := strings.Split(s, "\n")
-	if len(lines) == 0 {
-		return "", errors.New("no lines in output")
-	}
-	matches := regex.FindStringSubmatch(lines[0])
-	if len(matches) != 2 {
-		return "", errors.New("no matches")
-	}
-	return Version(matches[1]), nil
+// installedAlternative returns the first of alternatives that is installed, or an empty string if
+// none are.
+func installedAlternative(alternatives []string) string {
+	for _, alternative := range alternatives {
+		if _, err := lookPath(alternative); err == nil {
+			return alternative
+		}
+	}
+	return ""
 }
 
-func getLastWordOnFirstLine(s string) (string, error) {
-	lines := strings.Split(s, "\n")
-	if len(lines) == 0 {
-		return "", errors.New("no lines in output")
+// FindExecutables returns the paths of the executables in $PATH whose names match the glob
+// pattern, e.g. "python3.*". As with exec.LookPath, only the first executable with each name is
+// returned, and the paths are in $PATH order.
+func FindExecutables(pattern string) ([]string, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
 	}
-	words := strings.Split(lines[0], " ")
-	if len(words) == 0 {
-		return "", errors.New("no words in first line")
+
+	var paths []string
+	seen := make(map[string]bool)
+	for _, dir := range filepath.SplitList(os.Getenv("PATH")) {
+		if dir == "" {
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			name := filepath.Base(match)
+			if seen[name] || !isExecutableFile(match) {
+				continue
+			}
+			seen[name] = true
+			paths = append(paths, match)
+		}
+	}
+	return paths, nil
+}
+
+// isExecutableFile returns true if path is a regular file that is executable by anyone.
+func isExecutableFile(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().IsRegular() && info.Mode().Perm()&0o111 != 0
+}
+
+// isUnderDir returns true if path is dir or is inside it.
+func isUnderDir(path string, dir string) bool {
+	path, dir = filepath.Clean(path), filepath.Clean(dir)
+	return path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, string(filepath.Separator))+string(filepath.Separator))
+}
+
+// commandError returns ctx's error if ctx is done, because that is why the command failed, and
+// err otherwise.
+func commandError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("%w: %v", ctxErr, err)
 	}
-	return words[len(words)-1], nil
+	return err
 }
 
-func getProgramVersionOutput(p Program, zlog *zerolog.Logger) (string, error) {
-	var name string
-	var args []string
+// versionCommand returns the name of the executable to run to print the version of the program
+// described by spec and opts, and its arguments.
+func versionCommand(spec programSpec, opts IdentifyOptions) (string, []string) {
+	name := spec.name
+	if spec.command != "" {
+		name = spec.command
+	}
+	args := []string{"--version"}
+	if spec.args != nil {
+		args = spec.args
+	}
+	if opts.Args != nil {
+		args = opts.Args
+	}
+	if opts.Path != "" {
+		name = opts.Path
+	}
+	if len(opts.Invoker) > 0 {
+		args = append(append(append([]string{}, opts.Invoker[1:]...), name), args...)
+		name = opts.Invoker[0]
+	}
+	return name, args
+}
 
-	switch p {
-	case Make, Git, Bash, Protobuf, PkgConfig, Poetry:
-		name = GetProgramName(p)
-		args = []string{"--version"}
-	case Go:
-		name = GetProgramName(p)
-		args = []string{"version"}
+// getProgramVersionOutput runs the program's version command, and returns its output and the path
+// of the executable that was run.
+func (id *Identifier) getProgramVersionOutput(ctx context.Context, spec programSpec, opts IdentifyOptions, zlog *zerolog.Logger) (command.Output, string, error) {
+	name, args := versionCommand(spec, opts)
+	path, err := resolvePath(name, opts.PathPrefix, zlog)
+	if err != nil {
+		if errors.Is(err, ErrProgramNotInstalled) && opts.Path == "" && len(opts.Invoker) == 0 {
+			if alternative := installedAlternative(spec.alternatives); alternative != "" {
+				err = fmt.Errorf("%w; %s is installed and may be used instead", err, alternative)
+			}
+		}
+		return command.Output{}, "", err
 	}
 
-	output, err := command.RunCommand(name, args...)
+	// Version commands never take credentials, so we assume it is safe to log the full command
+	// line and its raw output. This only shows up with --verbose.
+	zlog.Debug().
+		Str("name", name).
+		Str("path", path).
+		Strs("args", args).
+		Msg("running version command")
+
+	output, err := id.run(ctx, path, args...)
 	if err != nil {
-		zlog.Debug().Str("output", output).Err(err).Msg("failed to run command")
-		return "", err
+		zlog.Debug().Str("stdout", output.Stdout).Str("stderr", output.Stderr).Err(err).Msg("failed to run command")
+		return command.Output{}, "", commandError(ctx, err)
 	}
-	return output, nil
+	zlog.Debug().Str("name", name).Str("stdout", output.Stdout).Str("stderr", output.Stderr).Msg("version command output")
+	return output, path, nil
 }
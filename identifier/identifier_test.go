@@ -0,0 +1,877 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package identifier
+
+import (
+	"context"
+	"errors"
+	"github.com/asimihsan/version-enforcer/command"
+	"github.com/rs/zerolog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestIdentifyGoModule(t *testing.T) {
+	zlog := zerolog.Nop()
+	output := "/Users/asim/go/bin/gopls: go1.21.0\n" +
+		"\tpath\tgolang.org/x/tools/gopls\n" +
+		"\tmod\tgolang.org/x/tools/gopls\tv0.13.2\th1:Pyvx6MKvatbX3zzZmdGiFRfQZl0ohPlt2dFBKqOvLUM=\n" +
+		"\tdep\tgithub.com/BurntSushi/toml\tv1.2.1\th1:9F2/+DoOYIOksmaJFPw1tGFy1eDnIJXg+UHjuD8lTak=\n" +
+		"\tbuild\t-compiler=gc\n"
+
+	actual, err := identifyGoModule(output, &zlog)
+	if err != nil {
+		t.Fatalf("identifyGoModule returned error: %v", err)
+	}
+	if actual.Version != "0.13.2" {
+		t.Errorf("identifyGoModule() = %s, want %s", actual.Version, "0.13.2")
+	}
+
+	devel := "/Users/asim/go/bin/mytool: go1.21.0\n\tpath\texample.com/mytool\n\tmod\texample.com/mytool\t(devel)\t\n"
+	if _, err := identifyGoModule(devel, &zlog); err == nil {
+		t.Errorf("identifyGoModule should return an error for a (devel) build")
+	}
+}
+
+func TestIdentifyWithArgs(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	var ranName string
+	var ranArgs []string
+	fakeLookPath(t, "/usr/bin/git")
+	defer func(original func(context.Context, string, ...string) (command.Output, error)) { runCommand = original }(runCommand)
+	runCommand = func(ctx context.Context, name string, arg ...string) (command.Output, error) {
+		ranName, ranArgs = name, arg
+		return command.Output{Stdout: "git version 2.39.1\n"}, nil
+	}
+
+	version, err := IdentifyWithArgs(Git, []string{"version"}, &zlog)
+	if err != nil {
+		t.Fatalf("IdentifyWithArgs returned error: %v", err)
+	}
+	if version.Version != "2.39.1" {
+		t.Errorf("IdentifyWithArgs() = %s, want %s", version.Version, "2.39.1")
+	}
+	if ranName != "/usr/bin/git" || strings.Join(ranArgs, " ") != "version" {
+		t.Errorf("ran %s %v, want /usr/bin/git [version]", ranName, ranArgs)
+	}
+
+	if _, err := Identify(Git, &zlog); err != nil {
+		t.Fatalf("Identify returned error: %v", err)
+	}
+	if strings.Join(ranArgs, " ") != "--version" {
+		t.Errorf("ran git %v, want the built-in args [--version]", ranArgs)
+	}
+}
+
+func TestIdentifyMethod(t *testing.T) {
+	zlog := zerolog.Nop()
+	fakeLookPath(t, "/usr/bin/git")
+	defer func(original func(context.Context, string, ...string) (command.Output, error)) { runCommand = original }(runCommand)
+	runCommand = func(ctx context.Context, name string, arg ...string) (command.Output, error) {
+		return command.Output{Stdout: "git version 2.39.1\n"}, nil
+	}
+	t.Setenv("GIT_VERSION", "2.39.1")
+
+	identification, err := Identify(Git, &zlog)
+	if want := "built-in `git --version`"; err != nil || identification.Method != want {
+		t.Errorf("Identify() method = %q, %v, want %q", identification.Method, err, want)
+	}
+	identification, err = IdentifyWithArgs(Git, []string{"version"}, &zlog)
+	if want := "custom arguments `git version`"; err != nil || identification.Method != want {
+		t.Errorf("IdentifyWithArgs() method = %q, %v, want %q", identification.Method, err, want)
+	}
+	identification, err = IdentifyEnv("GIT_VERSION", &zlog)
+	if want := "environment variable $GIT_VERSION"; err != nil || identification.Method != want {
+		t.Errorf("IdentifyEnv() method = %q, %v, want %q", identification.Method, err, want)
+	}
+
+	runCommand = func(ctx context.Context, name string, arg ...string) (command.Output, error) {
+		return command.Output{Stdout: "2.39.1\n"}, nil
+	}
+	identification, err = IdentifyProbe(context.Background(), "/repo/probe.sh", &zlog)
+	if want := "probe script /repo/probe.sh"; err != nil || identification.Method != want {
+		t.Errorf("IdentifyProbe() method = %q, %v, want %q", identification.Method, err, want)
+	}
+}
+
+func TestIdentifyMultiTokenCommand(t *testing.T) {
+	zlog := zerolog.Nop()
+	tests := []struct {
+		program  Program
+		path     string
+		stdout   string
+		args     string
+		expected Version
+	}{
+		{GitFlow, "/usr/bin/git", "1.12.3\n", "flow version", "1.12.3"},
+		{CargoDeny, "/usr/local/bin/cargo", "cargo-deny 0.14.2\n", "deny --version", "0.14.2"},
+		{CargoGenerate, "/usr/local/bin/cargo", "cargo generate 0.18.3\n", "generate --version", "0.18.3"},
+		{HelmDiff, "/usr/local/bin/helm", "3.8.1\n", "diff version", "3.8.1"},
+	}
+	defer func(original func(context.Context, string, ...string) (command.Output, error)) { runCommand = original }(runCommand)
+	for _, test := range tests {
+		name := GetProgramName(test.program)
+		var ranName string
+		var ranArgs []string
+		fakeLookPath(t, test.path)
+		runCommand = func(ctx context.Context, name string, arg ...string) (command.Output, error) {
+			ranName, ranArgs = name, arg
+			return command.Output{Stdout: test.stdout}, nil
+		}
+
+		identification, err := Identify(test.program, &zlog)
+		if err != nil {
+			t.Errorf("Identify(%s) returned error: %v", name, err)
+			continue
+		}
+		if identification.Version != test.expected {
+			t.Errorf("Identify(%s) = %s, want %s", name, identification.Version, test.expected)
+		}
+		if ranName != test.path || strings.Join(ranArgs, " ") != test.args {
+			t.Errorf("Identify(%s) ran %s %v, want %s [%s]", name, ranName, ranArgs, test.path, test.args)
+		}
+		if identification.Path != test.path {
+			t.Errorf("Identify(%s).Path = %q, want %q", name, identification.Path, test.path)
+		}
+	}
+}
+
+func TestIdentifyPreservesRawOutput(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	tests := []struct {
+		name   string
+		output command.Output
+		stream Stream
+		raw    string
+	}{
+		{
+			name:   "stdout",
+			output: command.Output{Stdout: "go version go1.21.0 darwin/arm64\n"},
+			stream: StreamStdout,
+			raw:    "go version go1.21.0 darwin/arm64",
+		},
+		{
+			name:   "stderr",
+			output: command.Output{Stderr: "Erlang (SMP,ASYNC_THREADS) (BEAM) emulator version 13.2\n"},
+			stream: StreamStderr,
+			raw:    "Erlang (SMP,ASYNC_THREADS) (BEAM) emulator version 13.2",
+		},
+		{
+			name: "stdout preferred over stderr",
+			output: command.Output{
+				Stdout: "go version go1.21.0 darwin/arm64\n",
+				Stderr: "go: warning: GOPATH set to GOROOT\n",
+			},
+			stream: StreamStdout,
+			raw:    "go version go1.21.0 darwin/arm64",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeLookPath(t, "/usr/local/bin/tool")
+			original := runCommand
+			runCommand = func(ctx context.Context, name string, arg ...string) (command.Output, error) {
+				return tt.output, nil
+			}
+			t.Cleanup(func() { runCommand = original })
+
+			program := Go
+			if tt.stream == StreamStderr {
+				program = Erlang
+			}
+			identification, err := IdentifyContext(context.Background(), program, &zlog)
+			if err != nil {
+				t.Fatalf("IdentifyContext returned error: %v", err)
+			}
+			if identification.Stream != tt.stream {
+				t.Errorf("IdentifyContext().Stream = %s, want %s", identification.Stream, tt.stream)
+			}
+			if identification.Raw != tt.raw {
+				t.Errorf("IdentifyContext().Raw = %q, want %q", identification.Raw, tt.raw)
+			}
+		})
+	}
+}
+
+func TestIdentifyWithOptionsPath(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	var lookedUp, ranName string
+	defer func(original func(string) (string, error)) { lookPath = original }(lookPath)
+	lookPath = func(file string) (string, error) {
+		lookedUp = file
+		return file, nil
+	}
+	defer func(original func(context.Context, string, ...string) (command.Output, error)) { runCommand = original }(runCommand)
+	runCommand = func(ctx context.Context, name string, arg ...string) (command.Output, error) {
+		ranName = name
+		return command.Output{Stdout: "GNU bash, version 5.2.15(1)-release (aarch64-apple-darwin22.1.0)\n"}, nil
+	}
+
+	version, err := IdentifyWithOptions(context.Background(), Bash, IdentifyOptions{Path: "/opt/homebrew/bin/bash"}, &zlog)
+	if err != nil {
+		t.Fatalf("IdentifyWithOptions returned error: %v", err)
+	}
+	if version.Version != "5.2.15" {
+		t.Errorf("IdentifyWithOptions() = %s, want %s", version.Version, "5.2.15")
+	}
+	if lookedUp != "/opt/homebrew/bin/bash" || ranName != "/opt/homebrew/bin/bash" {
+		t.Errorf("looked up %s and ran %s, want /opt/homebrew/bin/bash", lookedUp, ranName)
+	}
+}
+
+func TestIdentifyNotInstalled(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	defer func(original func(string) (string, error)) { lookPath = original }(lookPath)
+	lookPath = func(file string) (string, error) {
+		return "", &exec.Error{Name: file, Err: exec.ErrNotFound}
+	}
+	ran := false
+	defer func(original func(context.Context, string, ...string) (command.Output, error)) { runCommand = original }(runCommand)
+	runCommand = func(ctx context.Context, name string, arg ...string) (command.Output, error) {
+		ran = true
+		return command.Output{}, errors.New("should not run")
+	}
+
+	_, err := Identify(Protobuf, &zlog)
+	if !errors.Is(err, ErrProgramNotInstalled) {
+		t.Fatalf("Identify error = %v, want %v", err, ErrProgramNotInstalled)
+	}
+	if err.Error() != "program not installed: protoc not found in $PATH" {
+		t.Errorf("Identify error = %q, want it to name protoc", err)
+	}
+
+	_, err = IdentifyGoModule(context.Background(), "gopls", IdentifyOptions{}, &zlog)
+	if !errors.Is(err, ErrProgramNotInstalled) {
+		t.Errorf("IdentifyGoModule error = %v, want %v", err, ErrProgramNotInstalled)
+	}
+
+	if ran {
+		t.Errorf("a command was run for a program that is not installed")
+	}
+}
+
+func TestIdentifyNotInstalledSuggestsAlternative(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	installed := map[string]bool{"task": true}
+	defer func(original func(string) (string, error)) { lookPath = original }(lookPath)
+	lookPath = func(file string) (string, error) {
+		if installed[file] {
+			return "/usr/local/bin/" + file, nil
+		}
+		return "", &exec.Error{Name: file, Err: exec.ErrNotFound}
+	}
+
+	_, err := Identify(Make, &zlog)
+	if !errors.Is(err, ErrProgramNotInstalled) {
+		t.Fatalf("Identify error = %v, want %v", err, ErrProgramNotInstalled)
+	}
+	want := "program not installed: make not found in $PATH; task is installed and may be used instead"
+	if err.Error() != want {
+		t.Errorf("Identify error = %q, want %q", err, want)
+	}
+
+	installed["just"] = true
+	if _, err := Identify(Make, &zlog); err == nil || !strings.HasSuffix(err.Error(), "; just is installed and may be used instead") {
+		t.Errorf("Identify error = %v, want it to suggest just first", err)
+	}
+
+	installed = map[string]bool{}
+	if _, err := Identify(Make, &zlog); err == nil || strings.Contains(err.Error(), "instead") {
+		t.Errorf("Identify error = %v, want no suggestion", err)
+	}
+}
+
+func TestIdentifyNotInstalledOutput(t *testing.T) {
+	zlog := zerolog.Nop()
+	fakeLookPath(t, "/usr/local/bin/terraform")
+
+	var output command.Output
+	defer func(original func(context.Context, string, ...string) (command.Output, error)) { runCommand = original }(runCommand)
+	runCommand = func(ctx context.Context, name string, arg ...string) (command.Output, error) {
+		return output, nil
+	}
+
+	tests := []struct {
+		output command.Output
+		want   string
+	}{
+		{
+			command.Output{Stdout: "/usr/local/bin/terraform: line 3: tfenv: command not found\n"},
+			`program not installed: terraform printed "/usr/local/bin/terraform: line 3: tfenv: command not found"`,
+		},
+		{
+			command.Output{Stderr: "Terraform is NOT INSTALLED, run tfenv install\n"},
+			`program not installed: terraform printed "Terraform is NOT INSTALLED, run tfenv install"`,
+		},
+	}
+	for _, tt := range tests {
+		output = tt.output
+		_, err := Identify(Terraform, &zlog)
+		if !errors.Is(err, ErrProgramNotInstalled) {
+			t.Errorf("Identify(%q) error = %v, want %v", tt.output, err, ErrProgramNotInstalled)
+		} else if err.Error() != tt.want {
+			t.Errorf("Identify(%q) error = %q, want %q", tt.output, err, tt.want)
+		}
+	}
+
+	// A version is still found in output that mentions a phrase.
+	output = command.Output{Stdout: "Terraform v1.5.7\nprovider not installed: run terraform init\n"}
+	if identification, err := Identify(Terraform, &zlog); err != nil || identification.Version != "1.5.7" {
+		t.Errorf("Identify() = %s, %v, want %s", identification.Version, err, "1.5.7")
+	}
+
+	// The phrases can be configured per Identifier.
+	id := NewIdentifier()
+	id.NotInstalledPhrases = []string{"no terraform selected"}
+	output = command.Output{Stdout: "No Terraform selected\n"}
+	if _, err := id.Identify(Terraform, &zlog); !errors.Is(err, ErrProgramNotInstalled) {
+		t.Errorf("Identify() error = %v, want %v", err, ErrProgramNotInstalled)
+	}
+	output = command.Output{Stdout: "bash: terraform: command not found\n"}
+	if _, err := id.Identify(Terraform, &zlog); errors.Is(err, ErrProgramNotInstalled) {
+		t.Errorf("Identify() error = %v, want an error other than %v", err, ErrProgramNotInstalled)
+	}
+}
+
+func TestIdentifyResolvesPath(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	var lookedUp, ranName string
+	defer func(original func(string) (string, error)) { lookPath = original }(lookPath)
+	lookPath = func(file string) (string, error) {
+		lookedUp = file
+		return "/usr/local/bin/" + file, nil
+	}
+	defer func(original func(context.Context, string, ...string) (command.Output, error)) { runCommand = original }(runCommand)
+	runCommand = func(ctx context.Context, name string, arg ...string) (command.Output, error) {
+		ranName = name
+		return command.Output{Stdout: "libprotoc 3.21.12\n"}, nil
+	}
+
+	version, err := Identify(Protobuf, &zlog)
+	if err != nil {
+		t.Fatalf("Identify returned error: %v", err)
+	}
+	if version.Version != "3.21.12" {
+		t.Errorf("Identify() = %s, want %s", version.Version, "3.21.12")
+	}
+	if lookedUp != "protoc" || ranName != "/usr/local/bin/protoc" {
+		t.Errorf("looked up %s and ran %s, want protoc and /usr/local/bin/protoc", lookedUp, ranName)
+	}
+}
+
+func TestIdentifyPathPrefix(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	fakeLookPath(t, "/Users/asim/.asdf/shims/go")
+	defer func(original func(context.Context, string, ...string) (command.Output, error)) { runCommand = original }(runCommand)
+	runCommand = func(ctx context.Context, name string, arg ...string) (command.Output, error) {
+		return command.Output{Stdout: "go version go1.21.0 darwin/arm64\n"}, nil
+	}
+
+	_, err := IdentifyWithOptions(context.Background(), Go, IdentifyOptions{PathPrefix: "/usr/local/bin"}, &zlog)
+	if !errors.Is(err, ErrOutsidePathPrefix) {
+		t.Fatalf("IdentifyWithOptions error = %v, want %v", err, ErrOutsidePathPrefix)
+	}
+	if !strings.Contains(err.Error(), "/Users/asim/.asdf/shims/go") {
+		t.Errorf("IdentifyWithOptions error = %v, want it to name the resolved path", err)
+	}
+
+	version, err := IdentifyWithOptions(context.Background(), Go, IdentifyOptions{PathPrefix: "/Users/asim/.asdf/"}, &zlog)
+	if err != nil {
+		t.Fatalf("IdentifyWithOptions returned error: %v", err)
+	}
+	if version.Version != "1.21.0" {
+		t.Errorf("IdentifyWithOptions() = %s, want %s", version.Version, "1.21.0")
+	}
+}
+
+func TestIsUnderDir(t *testing.T) {
+	tests := []struct {
+		path     string
+		dir      string
+		expected bool
+	}{
+		{"/usr/local/bin/go", "/usr/local/bin", true},
+		{"/usr/local/bin/go", "/usr/local/bin/", true},
+		{"/usr/local/bin/go", "/usr/local", true},
+		{"/usr/local/binaries/go", "/usr/local/bin", false},
+		{"/usr/bin/go", "/usr/local/bin", false},
+		{"/usr/local/bin/../../bin/go", "/usr/local/bin", false},
+	}
+	for _, test := range tests {
+		actual := isUnderDir(test.path, test.dir)
+		if actual != test.expected {
+			t.Errorf("isUnderDir(%s, %s) = %t, want %t", test.path, test.dir, actual, test.expected)
+		}
+	}
+}
+
+// fakeLookPath makes every executable resolve to path for the duration of the test.
+func fakeLookPath(t *testing.T, path string) {
+	original := lookPath
+	lookPath = func(string) (string, error) { return path, nil }
+	t.Cleanup(func() { lookPath = original })
+}
+
+func TestIdentifyOutput(t *testing.T) {
+	zlog := zerolog.Nop()
+	tests := []struct {
+		program  Program
+		output   string
+		expected Version
+	}{
+		{Make, "GNU Make 4.4\nBuilt for aarch64-apple-darwin21.6.0\n", "4.4"},
+		{Make, "GNU Make 4.3 Built for x86_64-pc-linux-gnu\n", "4.3"},
+		{Git, "git version 2.39.1\n", "2.39.1"},
+		{Bash, "GNU bash, version 5.1.8(1)-release (aarch64-apple-darwin21.6.0)\nCopyright (C) 2022\n", "5.1.8"},
+		{Go, "go version go1.17.5 darwin/arm64\n", "1.17.5"},
+		{Protobuf, "libprotoc 3.19.1\n", "3.19.1"},
+		{Protobuf, "25.1\n", "25.1"},
+		{Protobuf, "libprotoc 3.12.4 (Ubuntu 3.12.4-1ubuntu7)\n", "3.12.4"},
+		{PkgConfig, "0.29.2\n", "0.29.2"},
+		{PkgConfig, "0.29.2 (Debian 0.29.2-1)\n", "0.29.2"},
+		{PkgConfig, "pkg-config version 0.29.2, built for linux\n", "0.29.2"},
+		{Poetry, "Poetry (version 1.3.2)\n", "1.3.2"},
+		{Bazel, "bazel 6.2.0\n", "6.2.0"},
+		{Bazel, "2023/08/01 10:00:00 Downloading https://releases.bazel.build/6.2.0/release/bazel-6.2.0\nbazel 6.2.0\n", "6.2.0"},
+		{Buf, "1.23.1\n", "1.23.1"},
+		{TerraformDocs, "terraform-docs version v0.16.0 5858f8c darwin/arm64\n", "0.16.0"},
+		{Ko, "0.14.1\n", "0.14.1"},
+		{Yq, "yq (https://github.com/mikefarah/yq/) version v4.34.2\n", "4.34.2"},
+		{Yq, "yq version 3.4.1\n", "3.4.1"},
+		{Yq, "yq 3.2.3\n", "3.2.3"},
+		{ProtocGenGo, "protoc-gen-go v1.28.1\n", "1.28.1"},
+		{Just, "just 1.14.0\n", "1.14.0"},
+		{Task, "Task version: v3.31.0 (h1:Xm0JA9RmbW1WVFpUKSHsmxaeS5vUY3jlQhkd0DTX3ZE=)\n", "3.31.0"},
+		{Task, "Task version: 3.12.0\n", "3.12.0"},
+		{GitFlow, "1.12.3\n", "1.12.3"},
+		{GitFlow, "1.12.3 (AVH Edition)\n", "1.12.3"},
+		{Cmake, "cmake version 3.27.1\n\nCMake suite maintained and supported by Kitware (kitware.com/cmake).\n", "3.27.1"},
+		{Ctest, "ctest version 3.27.1\n\nCMake suite maintained and supported by Kitware (kitware.com/cmake).\n", "3.27.1"},
+		{Cpack, "cpack version 3.27.1\n\nCMake suite maintained and supported by Kitware (kitware.com/cmake).\n", "3.27.1"},
+		{Pwsh, "PowerShell 7.3.6\n", "7.3.6"},
+		{Fish, "fish, version 3.6.1\n", "3.6.1"},
+		{Zsh, "zsh 5.9 (arm-apple-darwin22.1.0)\n", "5.9"},
+		{Zsh, "zsh 5.8.1 (x86_64-ubuntu-linux-gnu)\n", "5.8.1"},
+		{Serverless, "Running \"serverless\" from node_modules\nFramework Core: 3.34.0 (local) 3.33.0 (global)\nPlugin: 6.2.3\nSDK: 4.3.2\n", "3.34.0"},
+		{Leiningen, "Leiningen 2.10.0 on Java 17.0.8 OpenJDK 64-Bit Server VM\n", "2.10.0"},
+		{Erlang, "Erlang (SMP,ASYNC_THREADS) (BEAM) emulator version 13.2\n", "13.2"},
+		{Gleam, "gleam 0.30.5\n", "0.30.5"},
+		{Crystal, "Crystal 1.9.2 [1908c816f] (2023-07-19)\n\nLLVM: 15.0.7\nDefault target: aarch64-apple-darwin22.6.0\n", "1.9.2"},
+		{Nim, "Nim Compiler Version 2.0.0 [MacOSX: arm64]\nCompiled at 2023-08-01\n", "2.0.0"},
+		{Ocaml, "The OCaml toplevel, version 5.0.0\n", "5.0.0"},
+		{Opam, "2.1.5\n", "2.1.5"},
+		{DotnetEf, "\n                     _/\\__\n               ---==/    \\\\\n\nEntity Framework Core .NET Command-line Tools 7.0.10\n", "7.0.10"},
+		{ProtocGenDoc, "protoc-gen-doc version v1.5.1\n", "1.5.1"},
+		{Sccache, "sccache 0.5.4\n", "0.5.4"},
+		{Cross, "[cross] warning: unable to get metadata for package\n[cross] warning: using newer rustc `1.72.0` for the target\ncross 0.2.5\n[cross] note: Falling back to `cargo` on the host.\ncargo 1.72.0 (103a7ff2e 2023-08-15)\n", "0.2.5"},
+	}
+	for _, test := range tests {
+		name := GetProgramName(test.program)
+		actual, err := identifyOutput(programs[test.program], test.output, &zlog)
+		if err != nil {
+			t.Errorf("identifyOutput(%s) returned error: %v", name, err)
+			continue
+		}
+		if actual.Version != test.expected {
+			t.Errorf("identifyOutput(%s) = %s, want %s", name, actual.Version, test.expected)
+		}
+	}
+
+	if _, err := identifyOutput(programs[Make], "make: unrecognized option '--version'\n", &zlog); err == nil {
+		t.Errorf("identifyOutput(make) should reject output with no version, even with its fallback regex")
+	}
+	if _, err := identifyOutput(programs[Buf], "Failure: unknown flag\n", &zlog); err == nil {
+		t.Errorf("identifyOutput(buf) should reject output that is not a bare version")
+	}
+	if _, err := identifyOutput(programs[Opam], "opam: command not found\n", &zlog); err == nil {
+		t.Errorf("identifyOutput(opam) should reject output that is not a bare version")
+	}
+}
+
+func TestIdentifyEmptyOutput(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	for _, output := range []string{"", "\n  \n"} {
+		if _, err := identifyOutput(programs[Protobuf], output, &zlog); !errors.Is(err, ErrEmptyVersionOutput) {
+			t.Errorf("identifyOutput(protoc, %q) error = %v, want %v", output, err, ErrEmptyVersionOutput)
+		}
+	}
+
+	fakeLookPath(t, "/usr/local/bin/protoc")
+	original := runCommand
+	runCommand = func(ctx context.Context, name string, arg ...string) (command.Output, error) {
+		return command.Output{}, nil
+	}
+	t.Cleanup(func() { runCommand = original })
+	if _, err := Identify(Protobuf, &zlog); !errors.Is(err, ErrEmptyVersionOutput) {
+		t.Errorf("Identify(protoc) error = %v, want %v", err, ErrEmptyVersionOutput)
+	}
+}
+
+func TestHashiCorpVersion(t *testing.T) {
+	zlog := zerolog.Nop()
+	tests := []struct {
+		program         Program
+		output          string
+		expectedVersion Version
+		expectedCommit  string
+	}{
+		{Terraform, "Terraform v1.5.7\non darwin_arm64\n", "1.5.7", ""},
+		{Packer, "Packer v1.9.4\n", "1.9.4", ""},
+		{Vault, "Vault v1.14.1 (bf23fe8636b04d554c0fa35a756c75c2f59026c0), built 2023-07-21T10:15:14Z\n", "1.14.1", "bf23fe8636b04d554c0fa35a756c75c2f59026c0"},
+		{Consul, "Consul v1.16.1\nRevision e0ab4d29\nBuild Date 2023-08-05T21:56:29Z\n", "1.16.1", "e0ab4d29"},
+		{Vagrant, "Vagrant 2.3.7\n", "2.3.7", ""},
+	}
+	for _, test := range tests {
+		name := GetProgramName(test.program)
+		actual, err := identifyOutput(programs[test.program], test.output, &zlog)
+		if err != nil {
+			t.Errorf("identifyOutput(%s) returned error: %v", name, err)
+			continue
+		}
+		if actual.Version != test.expectedVersion || actual.Commit != test.expectedCommit {
+			t.Errorf("identifyOutput(%s) = %+v, want version %s and commit %q", name, actual, test.expectedVersion, test.expectedCommit)
+		}
+	}
+
+	if _, err := identifyOutput(programs[Terraform], "Packer v1.9.4\n", &zlog); err == nil {
+		t.Errorf("identifyOutput(terraform) should reject another tool's version output")
+	}
+}
+
+func TestIdentifyJava(t *testing.T) {
+	zlog := zerolog.Nop()
+	output := "Property settings:\n" +
+		"    file.encoding = UTF-8\n" +
+		"    java.class.version = 61.0\n" +
+		"    java.home = /Library/Java/JavaVirtualMachines/temurin-17.jdk/Contents/Home\n" +
+		"    java.runtime.version = 17.0.8+7\n" +
+		"    java.version = 17.0.8\n" +
+		"    java.version.date = 2023-07-18\n" +
+		"\n" +
+		"openjdk version \"17.0.8\" 2023-07-18\n" +
+		"OpenJDK Runtime Environment Temurin-17.0.8+7 (build 17.0.8+7)\n"
+
+	fakeLookPath(t, "/usr/bin/java")
+	defer func(original func(context.Context, string, ...string) (command.Output, error)) { runCommand = original }(runCommand)
+	runCommand = func(ctx context.Context, name string, arg ...string) (command.Output, error) {
+		return command.Output{Stderr: output}, nil
+	}
+
+	actual, err := Identify(Java, &zlog)
+	if err != nil {
+		t.Fatalf("Identify(Java) returned error: %v", err)
+	}
+	if actual.Version != "17.0.8" || actual.Stream != StreamStderr {
+		t.Errorf("Identify(Java) = %+v, want version 17.0.8 from stderr", actual)
+	}
+
+	legacy, err := identifyOutput(programs[Java], "Property settings:\n    java.version = 1.8.0_382\n", &zlog)
+	if err != nil {
+		t.Fatalf("identifyOutput(Java) returned error: %v", err)
+	}
+	if legacy.Version != "1.8.0" {
+		t.Errorf("identifyOutput(Java) = %s, want %s", legacy.Version, "1.8.0")
+	}
+}
+
+func TestPrograms(t *testing.T) {
+	for p, spec := range programs {
+		if spec.name == "" || spec.regex == nil || spec.installHint == "" {
+			t.Errorf("program %d is missing a name, regex, or install hint: %+v", p, spec)
+		}
+		if spec.regex != nil && spec.regex.NumSubexp() < 1 {
+			t.Errorf("%s regex %s has no capture group", spec.name, spec.regex)
+		}
+		for _, name := range append([]string{spec.name}, spec.aliases...) {
+			got, err := GetProgram(name)
+			if err != nil || *got != p {
+				t.Errorf("GetProgram(%s) = %v, %v, want %d", name, got, err, p)
+			}
+		}
+	}
+}
+
+func TestIdentifyCommit(t *testing.T) {
+	zlog := zerolog.Nop()
+	tests := []struct {
+		program         Program
+		output          string
+		expectedVersion Version
+		expectedCommit  string
+	}{
+		{
+			Helm,
+			`version.BuildInfo{Version:"v3.12.3", GitCommit:"3a31588ad33fe3b89af5a2a54ee1d25bfe6eaa5e", GitTreeState:"clean", GoVersion:"go1.20.7"}` + "\n",
+			"3.12.3",
+			"3a31588ad33fe3b89af5a2a54ee1d25bfe6eaa5e",
+		},
+		{Go, "go version devel go1.22-2f0c8b8f1c Thu Aug 31 17:18:42 2023 +0000 darwin/arm64\n", "1.22", "2f0c8b8f1c"},
+		{Go, "go version go1.21.0 darwin/arm64\n", "1.21.0", ""},
+	}
+	for _, test := range tests {
+		name := GetProgramName(test.program)
+		actual, err := identifyOutput(programs[test.program], test.output, &zlog)
+		if err != nil {
+			t.Errorf("identifyOutput(%s) returned error: %v", name, err)
+			continue
+		}
+		if actual.Version != test.expectedVersion || actual.Commit != test.expectedCommit {
+			t.Errorf("identifyOutput(%s) = %+v, want version %s and commit %q", name, actual, test.expectedVersion, test.expectedCommit)
+		}
+	}
+
+	output := "/Users/asim/go/bin/mytool: go1.21.0\n" +
+		"\tpath\texample.com/mytool\n" +
+		"\tmod\texample.com/mytool\tv1.2.3\th1:abc=\n" +
+		"\tbuild\tvcs=git\n" +
+		"\tbuild\tvcs.revision=2f0c8b8f1cbd6eab9c5ba5e87d1fbc8ef7e1d7a4\n"
+	actual, err := identifyGoModule(output, &zlog)
+	if err != nil {
+		t.Fatalf("identifyGoModule returned error: %v", err)
+	}
+	if actual.Version != "1.2.3" || actual.Commit != "2f0c8b8f1cbd6eab9c5ba5e87d1fbc8ef7e1d7a4" {
+		t.Errorf("identifyGoModule() = %+v, want version 1.2.3 and the vcs.revision commit", actual)
+	}
+}
+
+func TestIdentifyContextCanceled(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	fakeLookPath(t, "/usr/bin/git")
+	started := make(chan struct{})
+	defer func(original func(context.Context, string, ...string) (command.Output, error)) { runCommand = original }(runCommand)
+	runCommand = func(ctx context.Context, name string, arg ...string) (command.Output, error) {
+		close(started)
+		<-ctx.Done()
+		return command.Output{}, errors.New("signal: killed")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+
+	_, err := IdentifyContext(ctx, Git, &zlog)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("IdentifyContext error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestIdentifyProbe(t *testing.T) {
+	zlog := zerolog.Nop()
+	dir := t.TempDir()
+
+	probe := filepath.Join(dir, "get-mytool-version.sh")
+	if err := os.WriteFile(probe, []byte("#!/bin/sh\necho '  1.4.2 '\n"), 0o755); err != nil {
+		t.Fatalf("failed to write probe: %v", err)
+	}
+	identification, err := IdentifyProbe(context.Background(), probe, &zlog)
+	if err != nil {
+		t.Fatalf("IdentifyProbe returned error: %v", err)
+	}
+	if identification.Version != "1.4.2" {
+		t.Errorf("IdentifyProbe() = %s, want %s", identification.Version, "1.4.2")
+	}
+
+	empty := filepath.Join(dir, "empty.sh")
+	if err := os.WriteFile(empty, []byte("#!/bin/sh\necho >&2 not installed\n"), 0o755); err != nil {
+		t.Fatalf("failed to write probe: %v", err)
+	}
+	if _, err := IdentifyProbe(context.Background(), empty, &zlog); !errors.Is(err, ErrEmptyVersionOutput) {
+		t.Errorf("IdentifyProbe error = %v, want %v", err, ErrEmptyVersionOutput)
+	}
+}
+
+func TestIdentifyInvoker(t *testing.T) {
+	zlog := zerolog.Nop()
+	dir := t.TempDir()
+
+	// The fake invoker only prints a version when asked for git's version through "exec", as
+	// asdf exec would with a project-pinned git.
+	invoker := filepath.Join(dir, "fake-asdf")
+	script := "#!/bin/sh\n[ \"$*\" = \"exec git --version\" ] && echo 'git version 2.42.0'\n"
+	if err := os.WriteFile(invoker, []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write invoker: %v", err)
+	}
+
+	opts := IdentifyOptions{Invoker: []string{invoker, "exec"}}
+	identification, err := IdentifyWithOptions(context.Background(), Git, opts, &zlog)
+	if err != nil {
+		t.Fatalf("IdentifyWithOptions returned error: %v", err)
+	}
+	if identification.Version != "2.42.0" {
+		t.Errorf("IdentifyWithOptions() = %s, want %s", identification.Version, "2.42.0")
+	}
+
+	opts.Invoker = []string{filepath.Join(dir, "missing-mise"), "exec", "--"}
+	if _, err := IdentifyWithOptions(context.Background(), Git, opts, &zlog); !errors.Is(err, ErrProgramNotInstalled) {
+		t.Errorf("IdentifyWithOptions error = %v, want %v", err, ErrProgramNotInstalled)
+	}
+}
+
+func TestIdentifyEnv(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	t.Setenv("GO_VERSION", "1.21.3\n")
+	identification, err := IdentifyEnv("GO_VERSION", &zlog)
+	if err != nil {
+		t.Fatalf("IdentifyEnv returned error: %v", err)
+	}
+	if identification.Version != "1.21.3" {
+		t.Errorf("IdentifyEnv() = %s, want %s", identification.Version, "1.21.3")
+	}
+
+	t.Setenv("GO_VERSION", "")
+	os.Unsetenv("GO_VERSION")
+	_, err = IdentifyEnv("GO_VERSION", &zlog)
+	if !errors.Is(err, ErrVersionEnvUnset) {
+		t.Fatalf("IdentifyEnv error = %v, want %v", err, ErrVersionEnvUnset)
+	}
+	if !strings.Contains(err.Error(), "$GO_VERSION") {
+		t.Errorf("IdentifyEnv error = %v, want it to name the variable", err)
+	}
+}
+
+func TestFindExecutables(t *testing.T) {
+	first, second := t.TempDir(), t.TempDir()
+	for _, path := range []string{
+		filepath.Join(first, "python3.11"),
+		filepath.Join(second, "python3.10"),
+		filepath.Join(second, "python3.11"),
+		filepath.Join(second, "python3-config"),
+	} {
+		if err := os.WriteFile(path, []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatalf("failed to write %s: %v", path, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(first, "python3.12"), []byte("not executable"), 0o644); err != nil {
+		t.Fatalf("failed to write python3.12: %v", err)
+	}
+	t.Setenv("PATH", first+string(filepath.ListSeparator)+second)
+
+	paths, err := FindExecutables("python3.*")
+	if err != nil {
+		t.Fatalf("FindExecutables returned error: %v", err)
+	}
+	expected := []string{filepath.Join(first, "python3.11"), filepath.Join(second, "python3.10")}
+	if strings.Join(paths, " ") != strings.Join(expected, " ") {
+		t.Errorf("FindExecutables(python3.*) = %v, want %v", paths, expected)
+	}
+
+	paths, err = FindExecutables("ruby*")
+	if err != nil || len(paths) != 0 {
+		t.Errorf("FindExecutables(ruby*) = %v, %v, want no paths", paths, err)
+	}
+}
+
+func TestIdentifyBashNote(t *testing.T) {
+	zlog := zerolog.Nop()
+	fakeLookPath(t, "/bin/bash")
+
+	var output command.Output
+	defer func(original func(context.Context, string, ...string) (command.Output, error)) { runCommand = original }(runCommand)
+	runCommand = func(ctx context.Context, name string, arg ...string) (command.Output, error) {
+		return output, nil
+	}
+
+	tests := []struct {
+		stdout      string
+		wantVersion Version
+		wantNote    bool
+	}{
+		{"GNU bash, version 3.2.57(1)-release (arm64-apple-darwin23)\nCopyright (C) 2007 Free Software Foundation, Inc.\n", "3.2.57", true},
+		{"GNU bash, version 5.2.26(1)-release (aarch64-apple-darwin23.2.0)\n", "5.2.26", false},
+	}
+	for _, tt := range tests {
+		output = command.Output{Stdout: tt.stdout}
+		identification, err := Identify(Bash, &zlog)
+		if err != nil {
+			t.Fatalf("Identify() error = %v", err)
+		}
+		if identification.Version != tt.wantVersion {
+			t.Errorf("Identify() = %s, want %s", identification.Version, tt.wantVersion)
+		}
+		if gotNote := identification.Note != ""; gotNote != tt.wantNote {
+			t.Errorf("Identify(%s) note = %q, want note %t", tt.wantVersion, identification.Note, tt.wantNote)
+		}
+		if tt.wantNote && !strings.Contains(identification.Note, "/opt/homebrew/bin/bash") {
+			t.Errorf("Identify(%s) note = %q, want it to mention /opt/homebrew/bin/bash", tt.wantVersion, identification.Note)
+		}
+	}
+}
+
+func TestGetInstallHint(t *testing.T) {
+	for _, p := range Programs() {
+		if GetInstallHint(p) == "" {
+			t.Errorf("GetInstallHint(%s) is empty, want a built-in hint", GetProgramName(p))
+		}
+	}
+}
+
+// TestProgramsAreCompiled checks that every program in the table can find its version without
+// compiling anything when it is identified. Regexes in the table are compiled when the package is
+// initialized, so a bad pattern fails every test rather than only the identification that uses it.
+func TestProgramsAreCompiled(t *testing.T) {
+	for p, spec := range programs {
+		if spec.regex == nil && spec.parse == nil {
+			t.Errorf("program %s has neither a regex nor a parse function", spec.name)
+		}
+		if spec.regex != nil && spec.regex.NumSubexp() < 1 {
+			t.Errorf("program %s regex %q has no group for the version", GetProgramName(p), spec.regex)
+		}
+		if spec.fallbackRegex != nil && spec.fallbackRegex.NumSubexp() < 1 {
+			t.Errorf("program %s fallback regex %q has no group for the version", GetProgramName(p), spec.fallbackRegex)
+		}
+	}
+}
+
+// BenchmarkIdentifyOutput uses the regexes in the programs table, which are compiled once when the
+// package is initialized. BenchmarkIdentifyOutputCompilingRegex shows what compiling a regex for
+// every identification would cost instead.
+func BenchmarkIdentifyOutput(b *testing.B) {
+	zlog := zerolog.Nop()
+	for i := 0; i < b.N; i++ {
+		if _, err := identifyOutput(programs[Go], "go version go1.21.3 darwin/arm64\n", &zlog); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkIdentifyOutputCompilingRegex(b *testing.B) {
+	zlog := zerolog.Nop()
+	spec := programs[Go]
+	for i := 0; i < b.N; i++ {
+		spec.regex = regexp.MustCompile(programs[Go].regex.String())
+		if _, err := identifyOutput(spec, "go version go1.21.3 darwin/arm64\n", &zlog); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package identifier
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxLatestResponse is the most of a response that FetchLatestVersion reads. A GitHub release,
+// including its notes, is much smaller.
+const maxLatestResponse = 1 << 20
+
+var ErrNoLatestVersion = errors.New("no latest version in response")
+
+// FetchLatestVersion returns the latest release of a program, fetched from url. The response is
+// either a GitHub release, e.g. from https://api.github.com/repos/OWNER/REPO/releases/latest, whose
+// tag_name is the version, or plain text whose first line is the version. A "v" prefix is removed.
+// Set a deadline on ctx to limit how long the request may take.
+func FetchLatestVersion(ctx context.Context, url string) (Version, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("%w: %s returned %s", ErrNoLatestVersion, url, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxLatestResponse))
+	if err != nil {
+		return "", err
+	}
+
+	version := strings.TrimSpace(string(body))
+	if strings.HasPrefix(version, "{") {
+		var release struct {
+			TagName string `json:"tag_name"`
+		}
+		if err := json.Unmarshal(body, &release); err != nil {
+			return "", fmt.Errorf("%w: %s: %v", ErrNoLatestVersion, url, err)
+		}
+		version = strings.TrimSpace(release.TagName)
+	} else {
+		version = strings.TrimSpace(strings.SplitN(version, "\n", 2)[0])
+	}
+	if version == "" {
+		return "", fmt.Errorf("%w: %s", ErrNoLatestVersion, url)
+	}
+	return Version(trimVersionPrefix(version)), nil
+}
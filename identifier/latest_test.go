@@ -0,0 +1,65 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package identifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFetchLatestVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/text":
+			fmt.Fprint(w, "1.22.0\nreleased 2024-02-06\n")
+		case "/repos/golang/go/releases/latest":
+			fmt.Fprint(w, `{"tag_name": "v1.22.1", "name": "go 1.22.1", "body": "notes"}`)
+		case "/empty":
+			fmt.Fprint(w, `{"message": "Not Found"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path string
+		want Version
+	}{
+		{"/text", "1.22.0"},
+		{"/repos/golang/go/releases/latest", "1.22.1"},
+	}
+	for _, tt := range tests {
+		version, err := FetchLatestVersion(context.Background(), server.URL+tt.path)
+		if err != nil {
+			t.Errorf("FetchLatestVersion(%s) returned error: %v", tt.path, err)
+		} else if version != tt.want {
+			t.Errorf("FetchLatestVersion(%s) = %s, want %s", tt.path, version, tt.want)
+		}
+	}
+
+	for _, path := range []string{"/empty", "/missing"} {
+		if _, err := FetchLatestVersion(context.Background(), server.URL+path); !errors.Is(err, ErrNoLatestVersion) {
+			t.Errorf("FetchLatestVersion(%s) error = %v, want %v", path, err, ErrNoLatestVersion)
+		}
+	}
+}
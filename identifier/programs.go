@@ -0,0 +1,553 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package identifier
+
+import "regexp"
+
+// enum for Programs that we can identify
+type Program int
+
+const (
+	Make Program = iota
+	Git
+	Bash
+	Go
+	Protobuf
+	PkgConfig
+	Poetry
+	Serverless
+	Leiningen
+	Erlang
+	Gleam
+	Crystal
+	Nim
+	Ocaml
+	Opam
+	DotnetEf
+	ProtocGenDoc
+	CargoDeny
+	Sccache
+	Cross
+	Bazel
+	Buf
+	CargoGenerate
+	TerraformDocs
+	Helm
+	Ko
+	Yq
+	HelmDiff
+	Vagrant
+	Terraform
+	Packer
+	Vault
+	Consul
+	Java
+	ProtocGenGo
+	Just
+	Task
+	GitFlow
+	Cmake
+	Ctest
+	Cpack
+	Pwsh
+	Fish
+	Zsh
+)
+
+// programSpec describes how to run a program to print its version, and how to find the version in
+// the output.
+type programSpec struct {
+	// name is what the program is called in configs, and the executable that is run unless command
+	// is set.
+	name string
+
+	// aliases are other names that the program can be called in configs.
+	aliases []string
+
+	// command is the executable to run, if it is not name, e.g. "cargo" for "cargo-deny".
+	command string
+
+	// args are passed to the executable to print the version. Defaults to --version.
+	args []string
+
+	// regex captures the version in its first group. It is matched against the first line of the
+	// output, or against the whole output if allLines is set.
+	regex    *regexp.Regexp
+	allLines bool
+
+	// fallbackRegex, if set, is tried when regex finds nothing that parses as a version, e.g. when
+	// a distribution adds a word after the version that lastWord would capture instead.
+	fallbackRegex *regexp.Regexp
+
+	// parse, if set, is used instead of regex to find the version in the whole output.
+	parse func(string) (Version, error)
+
+	// commitRegex, if set, captures the commit the program was built from in its first group. It
+	// is matched against the whole output, and the commit is optional.
+	commitRegex *regexp.Regexp
+
+	// comparator compares versions of the program, if they are not semver, e.g. DateComparator.
+	comparator Comparator
+
+	// installHint is shown when the program is missing or has the wrong version.
+	installHint string
+
+	// note, if set, returns an informational note about the identified version, or an empty string.
+	note func(Version) string
+
+	// alternatives are programs that do the same job, e.g. "just" for "make". If the program is not
+	// installed but an alternative is, the error suggests the alternative.
+	alternatives []string
+}
+
+var (
+	// lastWord captures the last word of the first line, e.g. "2.39.1" in "git version 2.39.1".
+	lastWord = regexp.MustCompile(`(\S+)$`)
+
+	// cmakeVersion captures the version printed by cmake and the ctest and cpack tools bundled with
+	// it, e.g. "3.27.1" in "ctest version 3.27.1".
+	cmakeVersion = regexp.MustCompile(`version ([0-9]+\.[0-9]+\.[0-9]+)`)
+
+	// bareVersion captures a first line that is nothing but a version number, e.g. "2.1.5".
+	bareVersion = regexp.MustCompile(`^v?([0-9]+(?:\.[0-9]+)*)$`)
+
+	// firstVersion captures the first word of the first line that looks like a version, wherever
+	// it is, e.g. "0.29.2" in "pkg-config version 0.29.2, built for linux".
+	firstVersion = regexp.MustCompile(`(?:^|[\s(])v?([0-9]+\.[0-9]+(?:\.[0-9]+)*(?:-[0-9A-Za-z.]+)?)(?:[\s),;]|$)`)
+)
+
+// hashiCorpVersion returns a regex for the "<Tool> v1.2.3" first line that HashiCorp tools print,
+// e.g. "Terraform v1.5.7". The "v" is optional, because e.g. Vagrant prints "Vagrant 2.3.7".
+func hashiCorpVersion(tool string) *regexp.Regexp {
+	return regexp.MustCompile(`^` + regexp.QuoteMeta(tool) + ` v?([0-9]+(?:\.[0-9]+)*)`)
+}
+
+// bashNote explains that a bash older than 4 is probably the system bash on macOS, which is stuck at
+// 3.2 for licensing reasons.
+func bashNote(version Version) string {
+	v, err := ParseVersion(string(version))
+	if err != nil || v.Major >= 4 {
+		return ""
+	}
+	return "macOS ships bash 3.2 as /bin/bash; if a newer bash is installed, e.g. at /opt/homebrew/bin/bash, " +
+		"put it first in $PATH or set path to use it"
+}
+
+// programs is the table of every Program that can be identified. Adding a program whose version
+// output fits a regex is a matter of adding an entry here.
+var programs = map[Program]programSpec{
+	// GNU Make 4.4
+	// Built for aarch64-apple-darwin21.6.0
+	//
+	// Some builds add to the first line, e.g. "GNU Make 4.3 Built for x86_64-pc-linux-gnu".
+	Make: {
+		name:          "make",
+		regex:         lastWord,
+		fallbackRegex: firstVersion,
+		installHint:   "install with: brew install make, or apt-get install make",
+		alternatives:  []string{"just", "task"},
+	},
+
+	// git version 2.39.1
+	Git: {
+		name:        "git",
+		regex:       lastWord,
+		installHint: "install with: brew install git, or apt-get install git",
+	},
+
+	// GNU bash, version 5.1.8(1)-release (aarch64-apple-darwin21.6.0)
+	Bash: {
+		name:        "bash",
+		regex:       regexp.MustCompile(`GNU bash, version ([0-9]+\.[0-9]+\.[0-9]+)`),
+		installHint: "install with: brew install bash, or apt-get install bash",
+		note:        bashNote,
+	},
+
+	// go version go1.17.5 darwin/arm64
+	//
+	// Development builds have a commit but no patch version, e.g.
+	//
+	// go version devel go1.22-2f0c8b8f1c Thu Aug 31 17:18:42 2023 +0000 darwin/arm64
+	Go: {
+		name:        "go",
+		args:        []string{"version"},
+		regex:       regexp.MustCompile(`go version (?:devel )?go([0-9]+\.[0-9]+(?:\.[0-9]+)?)`),
+		commitRegex: regexp.MustCompile(`go version devel go[0-9.]+-([0-9a-f]+)`),
+		installHint: "install from https://go.dev/dl/, or with: brew install go",
+	},
+
+	// libprotoc 3.19.1
+	//
+	// Some CI images replace protoc with a script that prints only the version, e.g. 25.1, and
+	// some packages add to it, e.g. "libprotoc 3.12.4 (Ubuntu 3.12.4-1ubuntu7)".
+	Protobuf: {
+		name:          "protoc",
+		regex:         regexp.MustCompile(`^(?:libprotoc\s+)?v?([0-9]+(?:\.[0-9]+)*)$`),
+		fallbackRegex: firstVersion,
+		installHint:   "install with: brew install protobuf, or apt-get install protobuf-compiler",
+	},
+
+	// 0.29.2
+	//
+	// Some distributions add to it, e.g. "0.29.2 (Debian 0.29.2-1)".
+	PkgConfig: {
+		name:          "pkg-config",
+		regex:         lastWord,
+		fallbackRegex: firstVersion,
+		installHint:   "install with: brew install pkg-config, or apt-get install pkg-config",
+	},
+
+	// Poetry (version 1.3.2)
+	Poetry: {
+		name:        "poetry",
+		regex:       regexp.MustCompile(`Poetry \(version ([0-9]+\.[0-9]+\.[0-9]+)\)`),
+		installHint: "install with: pipx install poetry",
+	},
+
+	// Running "serverless" from node_modules
+	// Framework Core: 3.34.0 (local) 3.33.0 (global)
+	// Plugin: 6.2.3
+	Serverless: {
+		name:        "serverless",
+		aliases:     []string{"sls"},
+		regex:       regexp.MustCompile(`Framework Core:\s*([0-9]+\.[0-9]+\.[0-9]+)`),
+		allLines:    true,
+		installHint: "install with: npm install -g serverless",
+	},
+
+	// Leiningen 2.10.0 on Java 17.0.8 OpenJDK 64-Bit Server VM
+	Leiningen: {
+		name:        "lein",
+		regex:       regexp.MustCompile(`Leiningen ([0-9]+\.[0-9]+\.[0-9]+)`),
+		installHint: "install with: brew install leiningen",
+	},
+
+	// Erlang (SMP,ASYNC_THREADS) (BEAM) emulator version 13.2
+	//
+	// erl prints this to stderr, and the version is that of the emulator.
+	Erlang: {
+		name:        "erl",
+		args:        []string{"-version"},
+		regex:       regexp.MustCompile(`emulator version ([0-9]+\.[0-9]+)`),
+		installHint: "install with: brew install erlang, or apt-get install erlang",
+	},
+
+	// gleam 0.30.5
+	Gleam: {
+		name:        "gleam",
+		regex:       lastWord,
+		installHint: "install with: brew install gleam",
+	},
+
+	// Crystal 1.9.2 [1908c816f] (2023-07-19)
+	//
+	// LLVM: 15.0.7
+	Crystal: {
+		name:        "crystal",
+		regex:       regexp.MustCompile(`Crystal ([0-9]+\.[0-9]+\.[0-9]+)`),
+		installHint: "install with: brew install crystal",
+	},
+
+	// Nim Compiler Version 2.0.0 [MacOSX: arm64]
+	// Compiled at 2023-08-01
+	Nim: {
+		name:        "nim",
+		regex:       regexp.MustCompile(`Version ([0-9]+\.[0-9]+\.[0-9]+)`),
+		installHint: "install with: brew install nim, or choosenim",
+	},
+
+	// The OCaml toplevel, version 5.0.0
+	Ocaml: {
+		name:        "ocaml",
+		regex:       regexp.MustCompile(`version ([0-9.]+)`),
+		installHint: "install with: opam switch create <version>, or brew install ocaml",
+	},
+
+	// 2.1.5
+	Opam: {
+		name:        "opam",
+		regex:       bareVersion,
+		installHint: "install with: brew install opam, or apt-get install opam",
+	},
+
+	// Entity Framework Core .NET Command-line Tools 7.0.10
+	//
+	// dotnet may print a banner before the version.
+	DotnetEf: {
+		name:        "dotnet-ef",
+		command:     "dotnet",
+		args:        []string{"ef", "--version"},
+		regex:       regexp.MustCompile(`Command-line Tools\s+([0-9]+\.[0-9]+\.[0-9]+)`),
+		allLines:    true,
+		installHint: "install with: dotnet tool install --global dotnet-ef",
+	},
+
+	// protoc-gen-doc version v1.5.1
+	ProtocGenDoc: {
+		name:        "protoc-gen-doc",
+		regex:       regexp.MustCompile(`version v([0-9]+\.[0-9]+\.[0-9]+)`),
+		installHint: "install with: go install github.com/pseudomuto/protoc-gen-doc/cmd/protoc-gen-doc@latest",
+	},
+
+	// cargo-deny 0.14.2
+	CargoDeny: {
+		name:        "cargo-deny",
+		command:     "cargo",
+		args:        []string{"deny", "--version"},
+		regex:       lastWord,
+		installHint: "install with: cargo install --locked cargo-deny",
+	},
+
+	// sccache 0.5.4
+	Sccache: {
+		name:        "sccache",
+		regex:       lastWord,
+		installHint: "install with: cargo install sccache, or brew install sccache",
+	},
+
+	// [cross] warning: unable to get metadata for package
+	// cross 0.2.5
+	// [cross] note: Falling back to `cargo` on the host.
+	// cargo 1.72.0 (103a7ff2e 2023-08-15)
+	//
+	// cross may print warnings before its version, and also prints the version of cargo.
+	Cross: {
+		name:        "cross",
+		regex:       regexp.MustCompile(`(?m)^cross ([0-9]+\.[0-9]+\.[0-9]+)`),
+		allLines:    true,
+		installHint: "install with: cargo install cross",
+	},
+
+	// bazel 6.2.0
+	//
+	// bazelisk may print download progress before the version.
+	Bazel: {
+		name:        "bazel",
+		regex:       regexp.MustCompile(`(?m)^bazel ([0-9]+\.[0-9]+\.[0-9]+)`),
+		allLines:    true,
+		installHint: "install with: brew install bazelisk, or npm install -g @bazel/bazelisk",
+	},
+
+	// 1.23.1
+	Buf: {
+		name:        "buf",
+		regex:       bareVersion,
+		installHint: "install with: brew install bufbuild/buf/buf",
+	},
+
+	// cargo generate 0.18.3
+	CargoGenerate: {
+		name:        "cargo-generate",
+		command:     "cargo",
+		args:        []string{"generate", "--version"},
+		regex:       lastWord,
+		installHint: "install with: cargo install cargo-generate",
+	},
+
+	// terraform-docs version v0.16.0 5858f8c darwin/arm64
+	TerraformDocs: {
+		name:        "terraform-docs",
+		regex:       regexp.MustCompile(`version v([0-9]+\.[0-9]+\.[0-9]+)`),
+		installHint: "install with: brew install terraform-docs",
+	},
+
+	// version.BuildInfo{Version:"v3.12.3", GitCommit:"3a31588ad33fe3b89af5a2a54ee1d25bfe6eaa5e", GitTreeState:"clean", GoVersion:"go1.20.7"}
+	Helm: {
+		name:        "helm",
+		args:        []string{"version"},
+		regex:       regexp.MustCompile(`Version:"v([0-9]+\.[0-9]+\.[0-9]+)"`),
+		commitRegex: regexp.MustCompile(`GitCommit:"([0-9a-f]+)"`),
+		installHint: "install with: brew install helm",
+	},
+
+	// 0.14.1
+	Ko: {
+		name:        "ko",
+		args:        []string{"version"},
+		regex:       bareVersion,
+		installHint: "install with: brew install ko, or go install github.com/google/ko@latest",
+	},
+
+	// yq (https://github.com/mikefarah/yq/) version v4.34.2
+	//
+	// mikefarah/yq v3 prints "yq version 3.4.1", and the Python yq prints "yq 3.2.3".
+	Yq: {
+		name:        "yq",
+		regex:       regexp.MustCompile(`(?m)(?:version |^yq )v?([0-9]+\.[0-9]+\.[0-9]+)`),
+		allLines:    true,
+		installHint: "install with: brew install yq",
+	},
+
+	// 3.8.1
+	HelmDiff: {
+		name:        "helm-diff",
+		command:     "helm",
+		args:        []string{"diff", "version"},
+		regex:       bareVersion,
+		installHint: "install with: helm plugin install https://github.com/databus23/helm-diff",
+	},
+
+	// Vagrant 2.3.7
+	Vagrant: {
+		name:        "vagrant",
+		regex:       hashiCorpVersion("Vagrant"),
+		installHint: "install with: brew install hashicorp/tap/hashicorp-vagrant",
+	},
+
+	// Terraform v1.5.7
+	// on darwin_arm64
+	Terraform: {
+		name:        "terraform",
+		args:        []string{"version"},
+		regex:       hashiCorpVersion("Terraform"),
+		installHint: "install with: brew install hashicorp/tap/terraform",
+	},
+
+	// Packer v1.9.4
+	Packer: {
+		name:        "packer",
+		args:        []string{"version"},
+		regex:       hashiCorpVersion("Packer"),
+		installHint: "install with: brew install hashicorp/tap/packer",
+	},
+
+	// Vault v1.14.1 (bf23fe8636b04d554c0fa35a756c75c2f59026c0), built 2023-07-21T10:15:14Z
+	Vault: {
+		name:        "vault",
+		args:        []string{"version"},
+		regex:       hashiCorpVersion("Vault"),
+		commitRegex: regexp.MustCompile(`\(([0-9a-f]{7,40})\)`),
+		installHint: "install with: brew install hashicorp/tap/vault",
+	},
+
+	// Consul v1.16.1
+	// Revision e0ab4d29
+	// Build Date 2023-08-05T21:56:29Z
+	Consul: {
+		name:        "consul",
+		args:        []string{"version"},
+		regex:       hashiCorpVersion("Consul"),
+		commitRegex: regexp.MustCompile(`(?m)^Revision ([0-9a-f]+)`),
+		installHint: "install with: brew install hashicorp/tap/consul",
+	},
+
+	// Property settings:
+	//     file.encoding = UTF-8
+	//     java.home = /Library/Java/JavaVirtualMachines/temurin-17.jdk/Contents/Home
+	//     java.version = 17.0.8
+	//     ...
+	// openjdk version "17.0.8" 2023-07-18
+	//
+	// The java.version property is the version of the JDK that also provides javac, keytool, and
+	// jarsigner. It is more reliable than the banner, whose format differs between vendors. Java
+	// prints it to stderr.
+	Java: {
+		name:        "java",
+		aliases:     []string{"jdk"},
+		args:        []string{"-XshowSettings:properties", "-version"},
+		regex:       regexp.MustCompile(`(?m)^\s*java\.version = ([0-9]+(?:\.[0-9]+)*)`),
+		allLines:    true,
+		installHint: "install with: brew install --cask temurin, or apt-get install default-jdk",
+	},
+
+	// protoc-gen-go v1.28.1
+	ProtocGenGo: {
+		name:        "protoc-gen-go",
+		regex:       regexp.MustCompile(`^protoc-gen-go v([0-9]+\.[0-9]+\.[0-9]+)`),
+		installHint: "install with: go install google.golang.org/protobuf/cmd/protoc-gen-go@latest",
+	},
+
+	// just 1.14.0
+	Just: {
+		name:        "just",
+		regex:       lastWord,
+		installHint: "install with: brew install just, or cargo install just",
+	},
+
+	// Task version: v3.31.0 (h1:Xm0JA9RmbW1WVFpUKSHsmxaeS5vUY3jlQhkd0DTX3ZE=)
+	Task: {
+		name:        "task",
+		regex:       regexp.MustCompile(`^Task version: v?([0-9]+\.[0-9]+\.[0-9]+)`),
+		installHint: "install with: brew install go-task, or go install github.com/go-task/task/v3/cmd/task@latest",
+	},
+
+	// 1.12.3 (AVH Edition)
+	GitFlow: {
+		name:        "git-flow",
+		command:     "git",
+		args:        []string{"flow", "version"},
+		regex:       regexp.MustCompile(`^([0-9]+\.[0-9]+\.[0-9]+)`),
+		installHint: "install with: brew install git-flow-avh, or apt-get install git-flow",
+	},
+
+	// cmake version 3.27.1
+	//
+	// CMake suite maintained and supported by Kitware (kitware.com/cmake).
+	Cmake: {
+		name:        "cmake",
+		regex:       cmakeVersion,
+		installHint: "install with: brew install cmake, or apt-get install cmake",
+	},
+
+	// ctest version 3.27.1
+	Ctest: {
+		name:        "ctest",
+		regex:       cmakeVersion,
+		installHint: "installed with cmake: brew install cmake, or apt-get install cmake",
+	},
+
+	// cpack version 3.27.1
+	Cpack: {
+		name:        "cpack",
+		regex:       cmakeVersion,
+		installHint: "installed with cmake: brew install cmake, or apt-get install cmake",
+	},
+
+	// PowerShell 7.3.6
+	Pwsh: {
+		name:        "pwsh",
+		regex:       regexp.MustCompile(`PowerShell ([0-9]+\.[0-9]+\.[0-9]+)`),
+		installHint: "install from https://aka.ms/powershell, or with: brew install --cask powershell",
+	},
+
+	// fish, version 3.6.1
+	Fish: {
+		name:        "fish",
+		regex:       regexp.MustCompile(`^fish, version ([0-9]+(?:\.[0-9]+)*)`),
+		installHint: "install with: brew install fish, or apt-get install fish",
+	},
+
+	// zsh 5.9 (arm-apple-darwin22.1.0)
+	Zsh: {
+		name:        "zsh",
+		regex:       regexp.MustCompile(`^zsh ([0-9]+(?:\.[0-9]+)*)`),
+		installHint: "install with: brew install zsh, or apt-get install zsh",
+	},
+}
+
+// programNameToProgramMap maps the names and aliases of every program in the table to the Program.
+var programNameToProgramMap = func() map[string]Program {
+	m := make(map[string]Program)
+	for p, spec := range programs {
+		m[spec.name] = p
+		for _, alias := range spec.aliases {
+			m[alias] = p
+		}
+	}
+	return m
+}()
@@ -0,0 +1,177 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package identifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/asimihsan/version-enforcer/command"
+	"github.com/rs/zerolog"
+	"sort"
+)
+
+var (
+	ErrProgramAlreadyRegistered = errors.New("program already registered")
+	ErrNilParse                 = errors.New("parse must not be nil")
+)
+
+// Runner runs a command and returns what it wrote to stdout and stderr, like
+// command.RunCommandOutput.
+type Runner func(ctx context.Context, name string, arg ...string) (command.Output, error)
+
+// Identifier identifies programs from its own table of programs, so that programs can be
+// registered with one Identifier without affecting other Identifiers or the package functions.
+// The package functions use an Identifier with only the built-in programs.
+//
+// An Identifier may be used concurrently, but RegisterProgram must not be called while it is in
+// use.
+type Identifier struct {
+	programs map[Program]programSpec
+	names    map[string]Program
+
+	// Runner runs version commands. If nil, they are run with command.RunCommandOutput.
+	Runner Runner
+
+	// NotInstalledPhrases are phrases, matched ignoring case, that mean the program is not installed
+	// when they are in output that has no version, e.g. from a shim that exits 0 anyway.
+	NotInstalledPhrases []string
+}
+
+// DefaultNotInstalledPhrases are the NotInstalledPhrases of a new Identifier. Changing them does
+// not affect Identifiers that already exist, including the one used by the package functions.
+var DefaultNotInstalledPhrases = []string{
+	"command not found",
+	"not installed",
+	"no such file or directory",
+	"is not recognized as an internal or external command",
+}
+
+// NewIdentifier returns an Identifier for every built-in program.
+func NewIdentifier() *Identifier {
+	id := &Identifier{
+		programs: make(map[Program]programSpec, len(programs)),
+		names:    make(map[string]Program, len(programNameToProgramMap)),
+
+		NotInstalledPhrases: append([]string{}, DefaultNotInstalledPhrases...),
+	}
+	for p, spec := range programs {
+		id.programs[p] = spec
+	}
+	for name, p := range programNameToProgramMap {
+		id.names[name] = p
+	}
+	return id
+}
+
+// run runs a command with the Identifier's Runner.
+func (id *Identifier) run(ctx context.Context, name string, arg ...string) (command.Output, error) {
+	if id.Runner != nil {
+		return id.Runner(ctx, name, arg...)
+	}
+	return runCommand(ctx, name, arg...)
+}
+
+// RegisterProgram adds a program called name to the Identifier, and returns the new Program. The
+// program is run with versionArgs, or --version if versionArgs is nil, and parse returns the
+// version in its output, with surrounding whitespace trimmed.
+func (id *Identifier) RegisterProgram(name string, versionArgs []string, parse func(string) (Version, error)) (Program, error) {
+	if _, ok := id.names[name]; ok {
+		return 0, fmt.Errorf("%w: %s", ErrProgramAlreadyRegistered, name)
+	}
+	if parse == nil {
+		return 0, fmt.Errorf("%w: %s", ErrNilParse, name)
+	}
+
+	var p Program
+	for existing := range id.programs {
+		if existing >= p {
+			p = existing + 1
+		}
+	}
+	id.programs[p] = programSpec{name: name, args: versionArgs, parse: parse}
+	id.names[name] = p
+	return p, nil
+}
+
+// GetProgram returns the Program for the given name, if found.
+func (id *Identifier) GetProgram(programName string) (*Program, error) {
+	p, ok := id.names[programName]
+	if !ok {
+		return nil, errors.New("program not found")
+	}
+	return &p, nil
+}
+
+// Programs returns every Program that the Identifier can identify, sorted by name.
+func (id *Identifier) Programs() []Program {
+	all := make([]Program, 0, len(id.programs))
+	for p := range id.programs {
+		all = append(all, p)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return id.programs[all[i]].name < id.programs[all[j]].name
+	})
+	return all
+}
+
+// GetProgramName returns the name of the given Program.
+func (id *Identifier) GetProgramName(p Program) string {
+	return id.programs[p].name
+}
+
+// GetComparator returns the comparator for versions of the given Program.
+func (id *Identifier) GetComparator(p Program) Comparator {
+	if c := id.programs[p].comparator; c != nil {
+		return c
+	}
+	return SemverComparator{}
+}
+
+// GetInstallHint returns a hint for how to install the given Program, or an empty string if there
+// is none.
+func (id *Identifier) GetInstallHint(p Program) string {
+	return id.programs[p].installHint
+}
+
+// Identify returns the version of the program p, or an error if the program is not supported.
+func (id *Identifier) Identify(p Program, zlog *zerolog.Logger) (Identification, error) {
+	return id.IdentifyContext(context.Background(), p, zlog)
+}
+
+// IdentifyContext is like Identify, but kills the program and returns ctx's error if ctx is done
+// before the program exits.
+func (id *Identifier) IdentifyContext(ctx context.Context, p Program, zlog *zerolog.Logger) (Identification, error) {
+	return id.IdentifyWithOptions(ctx, p, IdentifyOptions{}, zlog)
+}
+
+// IdentifyWithArgs is like Identify, but runs the program with versionArgs instead of its built-in
+// arguments, if versionArgs is not nil.
+func (id *Identifier) IdentifyWithArgs(p Program, versionArgs []string, zlog *zerolog.Logger) (Identification, error) {
+	return id.IdentifyWithOptions(context.Background(), p, IdentifyOptions{Args: versionArgs}, zlog)
+}
+
+// IdentifyWithOptions is like IdentifyContext, but runs the program as described by opts.
+func (id *Identifier) IdentifyWithOptions(ctx context.Context, p Program, opts IdentifyOptions, zlog *zerolog.Logger) (Identification, error) {
+	spec, ok := id.programs[p]
+	if !ok {
+		zlog.Debug().Msg("program not supported")
+		return Identification{}, ErrProgramNotSupported
+	}
+	return id.identifySpec(ctx, spec, opts, zlog)
+}
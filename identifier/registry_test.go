@@ -0,0 +1,123 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package identifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/asimihsan/version-enforcer/command"
+	"github.com/rs/zerolog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestRegisterProgram(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	var ranName string
+	var ranArgs []string
+	fakeLookPath(t, "/opt/bin/mytool")
+	defer func(original func(context.Context, string, ...string) (command.Output, error)) { runCommand = original }(runCommand)
+	runCommand = func(ctx context.Context, name string, arg ...string) (command.Output, error) {
+		ranName, ranArgs = name, arg
+		return command.Output{Stdout: "mytool build 42 (release 3.1.4)\n"}, nil
+	}
+
+	id := NewIdentifier()
+	p, err := id.RegisterProgram("mytool", []string{"about"}, func(s string) (Version, error) {
+		start, end := strings.Index(s, "release "), strings.Index(s, ")")
+		if start < 0 || end < start {
+			return "", errors.New("no release")
+		}
+		return Version(s[start+len("release ") : end]), nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterProgram returned error: %v", err)
+	}
+
+	found, err := id.GetProgram("mytool")
+	if err != nil || *found != p {
+		t.Fatalf("GetProgram(mytool) = %v, %v, want %v", found, err, p)
+	}
+	if _, err := GetProgram("mytool"); err == nil {
+		t.Errorf("package GetProgram(mytool) should not find a program registered with an Identifier")
+	}
+
+	identification, err := id.IdentifyWithOptions(context.Background(), p, IdentifyOptions{}, &zlog)
+	if err != nil {
+		t.Fatalf("IdentifyWithOptions returned error: %v", err)
+	}
+	if identification.Version != "3.1.4" {
+		t.Errorf("IdentifyWithOptions() = %s, want %s", identification.Version, "3.1.4")
+	}
+	if ranName != "/opt/bin/mytool" || strings.Join(ranArgs, " ") != "about" {
+		t.Errorf("ran %s %v, want /opt/bin/mytool [about]", ranName, ranArgs)
+	}
+
+	if _, err := id.RegisterProgram("git", nil, func(string) (Version, error) { return "", nil }); !errors.Is(err, ErrProgramAlreadyRegistered) {
+		t.Errorf("RegisterProgram(git) error = %v, want %v", err, ErrProgramAlreadyRegistered)
+	}
+}
+
+func TestIdentifiersConcurrently(t *testing.T) {
+	zlog := zerolog.Nop()
+
+	newIdentifier := func(release string) (*Identifier, Program) {
+		id := NewIdentifier()
+		id.Runner = func(ctx context.Context, name string, arg ...string) (command.Output, error) {
+			return command.Output{Stdout: "mytool " + release + "\n"}, nil
+		}
+		p, err := id.RegisterProgram("mytool", nil, func(s string) (Version, error) {
+			return Version(strings.TrimPrefix(s, "mytool ")), nil
+		})
+		if err != nil {
+			t.Fatalf("RegisterProgram returned error: %v", err)
+		}
+		return id, p
+	}
+	ids := make([]*Identifier, 2)
+	ps := make([]Program, 2)
+	for i := range ids {
+		ids[i], ps[i] = newIdentifier(fmt.Sprintf("%d.0.0", i+1))
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for n := 0; n < 50; n++ {
+		for i := range ids {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				identification, err := ids[i].IdentifyWithOptions(context.Background(), ps[i], IdentifyOptions{Path: "/bin/sh"}, &zlog)
+				want := Version(fmt.Sprintf("%d.0.0", i+1))
+				if err != nil {
+					errs <- err
+				} else if identification.Version != want {
+					errs <- fmt.Errorf("IdentifyWithOptions() = %s, want %s", identification.Version, want)
+				}
+			}(i)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
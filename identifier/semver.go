@@ -19,20 +19,42 @@ package identifier
 
 import (
 	"errors"
+	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
 )
 
 var (
-	operatorRegex           = regexp.MustCompile(`([><=]{1,2})\s*(.*)`)
+	operatorRegex = regexp.MustCompile(`^([><=]{1,2})\s*(.*)$`)
+
+	// operatorOnlyRegex matches a word that is only an operator, which applies to the next word,
+	// e.g. ">=" in ">= 1.2 < 2".
+	operatorOnlyRegex       = regexp.MustCompile(`^(?:[><=]{1,2}|~>|~|\^)$`)
 	conditionOperatorToType = map[string]RequirementType{
+		"=":  SingleConditionEqual,
 		"==": SingleConditionEqual,
 		">":  SingleConditionGreaterThan,
 		"<":  SingleConditionLessThan,
 		">=": SingleConditionGreaterThanOrEqual,
 		"<=": SingleConditionLessThanOrEqual,
 	}
+
+	// versionPrefixes are stripped from the start of a version before parsing, e.g. "v1.2.3" or
+	// "go1.21.0". Only one prefix is stripped, and only when it is directly followed by a digit.
+	versionPrefixes = []string{"v", "go"}
+
+	// prereleaseRegex matches the dot-separated identifiers of a pre-release, e.g. "rc.1".
+	prereleaseRegex = regexp.MustCompile(`^[0-9A-Za-z-]+(?:\.[0-9A-Za-z-]+)*$`)
+)
+
+var (
+	ErrInvalidRequirement = errors.New("invalid requirement")
+	ErrInvalidOperator    = errors.New("invalid requirement operator")
+	ErrInvalidVersion     = errors.New("invalid version")
+	ErrNotStrictSemver    = errors.New("version is not major.minor.patch semver")
+	ErrTooFewComponents   = errors.New("version has too few components")
+	ErrInvalidRange       = errors.New("minimum version is greater than maximum version")
 )
 
 type RequirementType int
@@ -46,18 +68,104 @@ const (
 	SingleConditionLessThan
 	SingleConditionGreaterThanOrEqual
 	SingleConditionLessThanOrEqual
+	Pessimistic
+	// Range allows versions from Version, inclusive, up to MaxVersion, exclusive.
+	Range
 )
 
+// requirementTypeNames are the names of requirement types, as shown in explanations.
+var requirementTypeNames = map[RequirementType]string{
+	Exact:                             "exact",
+	Caret:                             "caret",
+	Tilde:                             "tilde",
+	SingleConditionEqual:              "equal",
+	SingleConditionGreaterThan:        "greater than",
+	SingleConditionLessThan:           "less than",
+	SingleConditionGreaterThanOrEqual: "greater than or equal",
+	SingleConditionLessThanOrEqual:    "less than or equal",
+	Pessimistic:                       "pessimistic",
+	Range:                             "range",
+}
+
+func (t RequirementType) String() string {
+	if name, ok := requirementTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("RequirementType(%d)", int(t))
+}
+
 type Requirement struct {
 	Type       RequirementType
 	Version    SemverVersion
 	MaxVersion *SemverVersion
 }
 
+// String returns the requirement as it would be written in a config, e.g. "^1.21" or
+// ">=1.2 <2.0", which SatisfiesE parses back into the same requirement.
+func (r Requirement) String() string {
+	switch r.Type {
+	case Caret:
+		return "^" + r.Version.String()
+	case Tilde:
+		return "~" + r.Version.String()
+	case Pessimistic:
+		return "~> " + r.Version.String()
+	case SingleConditionEqual:
+		return "=" + r.Version.String()
+	case SingleConditionGreaterThan:
+		return ">" + r.Version.String()
+	case SingleConditionLessThan:
+		return "<" + r.Version.String()
+	case SingleConditionGreaterThanOrEqual:
+		return ">=" + r.Version.String()
+	case SingleConditionLessThanOrEqual:
+		return "<=" + r.Version.String()
+	case Range:
+		return ">=" + r.Version.String() + " <" + r.MaxVersion.String()
+	default:
+		return r.Version.String()
+	}
+}
+
 type SemverVersion struct {
 	Major int
 	Minor *int
 	Patch *int
+
+	// Prerelease is the part after a hyphen, e.g. "rc.1" in "1.2.3-rc.1", or empty if the version
+	// is a release.
+	Prerelease string
+}
+
+// Components returns the number of components in the version, i.e. 1 for "3", 2 for "3.1", and 3
+// for "3.1.4".
+func (v SemverVersion) Components() int {
+	switch {
+	case v.Patch != nil:
+		return 3
+	case v.Minor != nil:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// String returns the version with as many components as it has, e.g. "3.1", followed by its
+// pre-release if it has one, e.g. "3.1.4-rc.1".
+func (v SemverVersion) String() string {
+	var s string
+	switch {
+	case v.Minor == nil:
+		s = strconv.Itoa(v.Major)
+	case v.Patch == nil:
+		s = fmt.Sprintf("%d.%d", v.Major, *v.Minor)
+	default:
+		s = fmt.Sprintf("%d.%d.%d", v.Major, *v.Minor, *v.Patch)
+	}
+	if v.Prerelease != "" {
+		s += "-" + v.Prerelease
+	}
+	return s
 }
 
 func CompareSemverVersions(a, b SemverVersion) int {
@@ -91,24 +199,81 @@ func CompareSemverVersions(a, b SemverVersion) int {
 	} else if b.Patch != nil {
 		return -1
 	}
-	return 0
+	return comparePrereleases(a.Prerelease, b.Prerelease)
+}
+
+// comparePrereleases compares the pre-releases of two versions that are otherwise equal, following
+// semver precedence: a release is greater than any of its pre-releases, and otherwise identifiers
+// are compared from left to right, numerically if both are numbers, with numbers lower than other
+// identifiers, and a pre-release with fewer identifiers lower if all of them are equal.
+func comparePrereleases(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				return compareInts(an, bn)
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		case as[i] != bs[i]:
+			return strings.Compare(as[i], bs[i])
+		}
+	}
+	return compareInts(len(as), len(bs))
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
 }
 
 func NewRequirement(s string) (*Requirement, error) {
+	s = strings.TrimSpace(s)
+
 	if strings.HasPrefix(s, "^") {
 		version, err := ParseVersion(s[1:])
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalidRequirement, s, err)
 		}
 		return &Requirement{
 			Type:    Caret,
 			Version: *version,
 		}, nil
 	}
+	// "~>" must be checked before "~".
+	if strings.HasPrefix(s, "~>") {
+		version, err := ParseVersion(s[2:])
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalidRequirement, s, err)
+		}
+		return &Requirement{
+			Type:    Pessimistic,
+			Version: *version,
+		}, nil
+	}
 	if strings.HasPrefix(s, "~") {
 		version, err := ParseVersion(s[1:])
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalidRequirement, s, err)
 		}
 		return &Requirement{
 			Type:    Tilde,
@@ -116,27 +281,32 @@ func NewRequirement(s string) (*Requirement, error) {
 		}, nil
 	}
 
+	// The operator, if any, must be at the start of the requirement and be followed only by a
+	// version, so that e.g. "1.2>3" is rejected rather than being read as ">3".
 	matches := operatorRegex.FindStringSubmatch(s)
 	if len(matches) == 3 {
 		operator := matches[1]
-		version := matches[2]
-
 		requirementType, ok := conditionOperatorToType[operator]
-		if ok {
-			version, err := ParseVersion(version)
-			if err != nil {
-				return nil, err
-			}
-			return &Requirement{
-				Type:    requirementType,
-				Version: *version,
-			}, nil
+		if !ok {
+			return nil, fmt.Errorf("%w %q in %q", ErrInvalidOperator, operator, s)
 		}
+		version, err := ParseVersion(matches[2])
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalidRequirement, s, err)
+		}
+		return &Requirement{
+			Type:    requirementType,
+			Version: *version,
+		}, nil
+	}
+
+	if strings.ContainsAny(s, "<>=") {
+		return nil, fmt.Errorf("%w %q: operator must be at the start of the requirement", ErrInvalidRequirement, s)
 	}
 
 	version, err := ParseVersion(s)
 	if err != nil {
-		return nil, err
+		return nil, fmt.Errorf("%w %q: %v", ErrInvalidRequirement, s, err)
 	}
 
 	return &Requirement{
@@ -145,6 +315,27 @@ func NewRequirement(s string) (*Requirement, error) {
 	}, nil
 }
 
+// NewRangeRequirement returns a requirement that allows versions from min, inclusive, up to max,
+// exclusive, e.g. min "1.2" and max "2.0" allow 1.2.0 up to but not including 2.0.0.
+func NewRangeRequirement(min string, max string) (*Requirement, error) {
+	minVersion, err := ParseVersion(min)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %v", ErrInvalidVersion, min, err)
+	}
+	maxVersion, err := ParseVersion(max)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %v", ErrInvalidVersion, max, err)
+	}
+	if CompareSemverVersions(zeroFilled(*minVersion), zeroFilled(*maxVersion)) > 0 {
+		return nil, fmt.Errorf("%w: %q > %q", ErrInvalidRange, min, max)
+	}
+	return &Requirement{
+		Type:       Range,
+		Version:    *minVersion,
+		MaxVersion: maxVersion,
+	}, nil
+}
+
 func mustParseVersion(s string) *SemverVersion {
 	v, err := ParseVersion(s)
 	if err != nil {
@@ -153,9 +344,47 @@ func mustParseVersion(s string) *SemverVersion {
 	return v
 }
 
+// ParseStrictVersion is like ParseVersion, but requires all of the major, minor, and patch
+// components, e.g. "1.2.3" but not "1.2" or "2023.05".
+func ParseStrictVersion(s string) (*SemverVersion, error) {
+	v, err := ParseVersion(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %v", ErrInvalidVersion, s, err)
+	}
+	if v.Minor == nil || v.Patch == nil {
+		return nil, fmt.Errorf("%w: %q", ErrNotStrictSemver, s)
+	}
+	return v, nil
+}
+
+// CheckMinComponents returns an error if version has fewer than min components, e.g. if a tool
+// that used to print "3.1.4" now prints "3".
+func CheckMinComponents(version string, min int) error {
+	v, err := ParseVersion(version)
+	if err != nil {
+		return fmt.Errorf("%w %q: %v", ErrInvalidVersion, version, err)
+	}
+	if v.Components() < min {
+		return fmt.Errorf("%w: %q has %d, want at least %d", ErrTooFewComponents, version, v.Components(), min)
+	}
+	return nil
+}
+
 func ParseVersion(s string) (*SemverVersion, error) {
 	s = strings.TrimSpace(s)
-	s = strings.TrimPrefix(s, "v")
+	s = trimVersionPrefix(s)
+
+	// Build metadata, e.g. "+build.5", does not affect precedence, so it is ignored.
+	if i := strings.Index(s, "+"); i >= 0 {
+		s = s[:i]
+	}
+	var prerelease string
+	if i := strings.Index(s, "-"); i >= 0 {
+		s, prerelease = s[:i], s[i+1:]
+		if !prereleaseRegex.MatchString(prerelease) {
+			return nil, fmt.Errorf("invalid pre-release %q", prerelease)
+		}
+	}
 
 	// Split into major.minor.patch
 	parts := strings.SplitN(s, ".", 3)
@@ -196,22 +425,37 @@ func ParseVersion(s string) (*SemverVersion, error) {
 
 	if isMinorSet && isPatchSet {
 		return &SemverVersion{
-			Major: major,
-			Minor: &minor,
-			Patch: &patch,
+			Major:      major,
+			Minor:      &minor,
+			Patch:      &patch,
+			Prerelease: prerelease,
 		}, nil
 	} else if isMinorSet {
 		return &SemverVersion{
-			Major: major,
-			Minor: &minor,
+			Major:      major,
+			Minor:      &minor,
+			Prerelease: prerelease,
 		}, nil
 	} else {
 		return &SemverVersion{
-			Major: major,
+			Major:      major,
+			Prerelease: prerelease,
 		}, nil
 	}
 }
 
+// trimVersionPrefix strips the first matching entry of versionPrefixes from s, if the remainder
+// starts with a digit. Otherwise s is returned unchanged.
+func trimVersionPrefix(s string) string {
+	for _, prefix := range versionPrefixes {
+		rest := strings.TrimPrefix(s, prefix)
+		if rest != s && len(rest) > 0 && rest[0] >= '0' && rest[0] <= '9' {
+			return rest
+		}
+	}
+	return s
+}
+
 // Satisfies returns true if the version matches the semver. version is the version of the
 // program, and requirement is a semver requirement. The semver requirement is a string that
 // follows the conventions in https://doc.rust-lang.org/cargo/reference/specifying-dependencies.html.
@@ -224,50 +468,236 @@ func ParseVersion(s string) (*SemverVersion, error) {
 // - 1.2.3 matches ~1.2
 // - 1.2.3 matches ~1
 // - 1.2.3 does not match ~2
+// - 1.2 matches ~1.2.3, because the version does not say which patch it is
+// - 1.9.0 matches ~> 1.2, the Ruby and Terraform pessimistic operator meaning >= 1.2, < 2.0
+// - 1.3.0 does not match ~> 1.2.3, which means >= 1.2.3, < 1.3.0
+// - 1.21.5 matches =1.20.3 || =1.21.5, which matches any of the alternatives separated by "||"
+// - 1.5.0 matches >=1.2.0 <2.0.0, which matches all of the clauses separated by spaces
+// - 1.2.3-rc1 does not match >=1.0.0, which does not mention a pre-release of 1.2.3
+// - 1.2.3-rc1 matches ^1.2.3-rc0, and so does 1.2.3, but 1.2.3-alpha does not
 func Satisfies(version string, requirement string) bool {
-	req, err := NewRequirement(requirement)
+	satisfied, err := SatisfiesE(version, requirement)
+	return err == nil && satisfied
+}
+
+// SatisfiesE is like Satisfies, but returns an error if the version or requirement cannot be
+// parsed rather than treating them as not satisfied.
+func SatisfiesE(version string, requirement string) (bool, error) {
+	reqs, err := parseAlternatives(requirement)
 	if err != nil {
-		return false
+		return false, err
 	}
+
+	// The version is parsed once, rather than once for every alternative.
 	v, err := ParseVersion(version)
 	if err != nil {
+		return false, fmt.Errorf("%w %q: %v", ErrInvalidVersion, version, err)
+	}
+	for _, clauses := range reqs {
+		if satisfiesAll(*v, clauses) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// parseAlternatives parses each of the alternatives of a requirement separated by "||" into its
+// clauses separated by spaces.
+func parseAlternatives(requirement string) ([][]*Requirement, error) {
+	alternatives, err := splitAlternatives(requirement)
+	if err != nil {
+		return nil, err
+	}
+	reqs := make([][]*Requirement, 0, len(alternatives))
+	for _, alternative := range alternatives {
+		var clauses []*Requirement
+		for _, clause := range splitClauses(alternative) {
+			req, err := NewRequirement(clause)
+			if err != nil {
+				return nil, err
+			}
+			clauses = append(clauses, req)
+		}
+		reqs = append(reqs, clauses)
+	}
+	return reqs, nil
+}
+
+// satisfiesAll returns true if v satisfies every one of reqs.
+func satisfiesAll(v SemverVersion, reqs []*Requirement) bool {
+	if !prereleaseAllowed(v, reqs...) {
 		return false
 	}
+	for _, req := range reqs {
+		if !satisfies(v, *req) {
+			return false
+		}
+	}
+	return true
+}
 
-	switch req.Type {
-	case Exact, Caret:
-		return CompareSemverVersions(*v, req.Version) == 0
+// prereleaseAllowed returns true if v is a release, or if one of reqs mentions a pre-release with
+// the same major.minor.patch as v. As in npm, a pre-release does not satisfy a requirement just
+// because it falls in range, e.g. 2.0.0-rc1 does not satisfy ">=1.0.0", so that opting in to the
+// pre-releases of one version does not opt in to the pre-releases of every version.
+func prereleaseAllowed(v SemverVersion, reqs ...*Requirement) bool {
+	if v.Prerelease == "" {
+		return true
+	}
+	release := v
+	release.Prerelease = ""
+	for _, req := range reqs {
+		bounds := []*SemverVersion{&req.Version, req.MaxVersion}
+		for _, bound := range bounds {
+			if bound == nil || bound.Prerelease == "" {
+				continue
+			}
+			boundRelease := *bound
+			boundRelease.Prerelease = ""
+			if CompareSemverVersions(zeroFilled(release), zeroFilled(boundRelease)) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
 
-	case Tilde:
-		// If req only has major version, then major versions must match.
-		if req.Version.Minor == nil && req.Version.Patch == nil {
-			return req.Version.Major == v.Major
+// splitClauses splits an alternative into the clauses separated by spaces, all of which must be
+// satisfied, e.g. ">=1.2.0 <2.0.0" as npm allows. An operator separated from its version by a
+// space stays with it, so ">= 1.2.0 < 2.0.0" has the same two clauses.
+func splitClauses(alternative string) []string {
+	words := strings.Fields(alternative)
+	if len(words) == 0 {
+		return []string{alternative}
+	}
+	clauses := make([]string, 0, len(words))
+	for i := 0; i < len(words); i++ {
+		if operatorOnlyRegex.MatchString(words[i]) && i+1 < len(words) {
+			clauses = append(clauses, words[i]+" "+words[i+1])
+			i++
+			continue
+		}
+		clauses = append(clauses, words[i])
+	}
+	return clauses
+}
+
+// AllOf returns a single requirement that is satisfied by the versions that satisfy every one of
+// requirements, e.g. ">=1.2 <2.0" for ">=1.2" and "<2.0". Alternatives are distributed over the
+// other requirements, so "=1.20.3 || =1.21.5" and "<1.21" give "=1.20.3 <1.21 || =1.21.5 <1.21".
+func AllOf(requirements ...string) string {
+	combined := []string{""}
+	for _, requirement := range requirements {
+		alternatives := strings.Split(requirement, "||")
+		next := make([]string, 0, len(combined)*len(alternatives))
+		for _, prefix := range combined {
+			for _, alternative := range alternatives {
+				next = append(next, strings.TrimSpace(prefix+" "+strings.TrimSpace(alternative)))
+			}
 		}
+		combined = next
+	}
+	return strings.Join(combined, " || ")
+}
 
-		// If req only has major and minor versions, then major and minor versions must match.
-		if req.Version.Patch == nil {
-			return req.Version.Major == v.Major && (v.Minor == nil || *req.Version.Minor == *v.Minor)
+// splitAlternatives splits a requirement into the alternatives separated by "||", any of which
+// may be satisfied, e.g. "=1.20.3 || =1.21.5".
+func splitAlternatives(requirement string) ([]string, error) {
+	alternatives := strings.Split(requirement, "||")
+	for i, alternative := range alternatives {
+		alternatives[i] = strings.TrimSpace(alternative)
+		if alternatives[i] == "" && len(alternatives) > 1 {
+			return nil, fmt.Errorf("%w %q: empty alternative", ErrInvalidRequirement, requirement)
 		}
+	}
+	return alternatives, nil
+}
+
+// SatisfiesRequirement is like SatisfiesE, but takes a parsed requirement, e.g. one returned by
+// NewRangeRequirement.
+func SatisfiesRequirement(version string, req Requirement) (bool, error) {
+	v, err := ParseVersion(version)
+	if err != nil {
+		return false, fmt.Errorf("%w %q: %v", ErrInvalidVersion, version, err)
+	}
+	return prereleaseAllowed(*v, &req) && satisfies(*v, req), nil
+}
+
+func satisfies(v SemverVersion, req Requirement) bool {
+	switch req.Type {
+	case Exact:
+		return CompareSemverVersions(v, req.Version) == 0
+	case Caret:
+		return satisfiesCaret(v, req.Version)
 
-		// If req has all of major, minor, and patch, then the version must have the same
-		// major and minor versions, and the patch version must be greater than or equal to
-		// the patch version in the requirement.
-		return req.Version.Major == v.Major &&
-			(v.Minor == nil ||
-				(*req.Version.Minor == *v.Minor &&
-					CompareSemverVersions(*v, req.Version) >= 0))
+	case Tilde:
+		return satisfiesTilde(v, req.Version)
+	case Pessimistic:
+		return satisfiesPessimistic(v, req.Version)
 
 	case SingleConditionEqual:
-		return CompareSemverVersions(*v, req.Version) == 0
+		return CompareSemverVersions(v, req.Version) == 0
 	case SingleConditionGreaterThan:
-		return CompareSemverVersions(*v, req.Version) > 0
+		return CompareSemverVersions(v, req.Version) > 0
 	case SingleConditionLessThan:
-		return CompareSemverVersions(*v, req.Version) < 0
+		return CompareSemverVersions(v, req.Version) < 0
 	case SingleConditionGreaterThanOrEqual:
-		return CompareSemverVersions(*v, req.Version) >= 0
+		return CompareSemverVersions(v, req.Version) >= 0
 	case SingleConditionLessThanOrEqual:
-		return CompareSemverVersions(*v, req.Version) <= 0
+		return CompareSemverVersions(v, req.Version) <= 0
+
+	case Range:
+		return CompareSemverVersions(zeroFilled(v), zeroFilled(req.Version)) >= 0 &&
+			CompareSemverVersions(zeroFilled(v), zeroFilled(*req.MaxVersion)) < 0
 	}
 
 	return false
 }
+
+// satisfiesCaret returns true if v is req. If req is a pre-release, later pre-releases of the same
+// version, and the version itself, also satisfy it, so that e.g. "^1.2.3-rc.0" allows 1.2.3-rc.1
+// and 1.2.3.
+func satisfiesCaret(v, req SemverVersion) bool {
+	if req.Prerelease == "" {
+		return CompareSemverVersions(v, req) == 0
+	}
+	release := req
+	release.Prerelease = ""
+	return CompareSemverVersions(v, req) >= 0 && CompareSemverVersions(v, release) <= 0
+}
+
+// satisfiesTilde returns true if v has the same major version as req, the same minor version if req
+// has one, and a patch version at least req's patch version if req has one.
+//
+// A component that v lacks is treated as compatible with req, so that e.g. "1.2" satisfies
+// "~1.2.3" and "1" satisfies "~1.2". The program did not report the component, so it cannot be
+// shown to be wrong. Use --min-found-digits to reject such versions instead.
+func satisfiesTilde(v, req SemverVersion) bool {
+	if v.Major != req.Major {
+		return false
+	}
+	if req.Minor == nil || v.Minor == nil {
+		return true
+	}
+	if *v.Minor != *req.Minor {
+		return false
+	}
+	if req.Patch == nil || v.Patch == nil {
+		return true
+	}
+	return *v.Patch > *req.Patch || (*v.Patch == *req.Patch && comparePrereleases(v.Prerelease, req.Prerelease) >= 0)
+}
+
+// satisfiesPessimistic implements the "~>" operator used by Ruby and Terraform. v must be at least
+// req, and may only increase the last component that req specifies, so that "~> 1.2" means
+// ">= 1.2, < 2.0" and "~> 1.2.3" means ">= 1.2.3, < 1.3.0". Unlike "~", a version that is less
+// precise than req is compared as if its missing components were lower.
+func satisfiesPessimistic(v, req SemverVersion) bool {
+	if CompareSemverVersions(v, req) < 0 || v.Major != req.Major {
+		return false
+	}
+	if req.Minor != nil && req.Patch != nil {
+		return v.Minor != nil && *v.Minor == *req.Minor
+	}
+	return true
+}
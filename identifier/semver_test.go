@@ -18,6 +18,7 @@
 package identifier
 
 import (
+	"errors"
 	"strings"
 	"testing"
 )
@@ -61,6 +62,199 @@ func TestDoesSemverMatch(t *testing.T) {
 	}
 }
 
+func TestParseVersionPrefixes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"go1.21.0", "1.21.0"},
+		{"v1.2.3", "1.2.3"},
+		{"1.2.3", "1.2.3"},
+	}
+
+	for _, test := range tests {
+		actual, err := ParseVersion(test.input)
+		if err != nil {
+			t.Errorf("ParseVersion(%s) returned error: %v", test.input, err)
+			continue
+		}
+		expected := mustParseVersion(test.expected)
+		if CompareSemverVersions(*actual, *expected) != 0 {
+			t.Errorf("ParseVersion(%s) = %+v, want %+v", test.input, *actual, *expected)
+		}
+	}
+
+	// Prefixes are only stripped when followed by a digit.
+	for _, input := range []string{"go", "gov1.2", "version1.2"} {
+		if _, err := ParseVersion(input); err == nil {
+			t.Errorf("ParseVersion(%s) should have returned an error", input)
+		}
+	}
+}
+
+func TestNewRequirementOperatorAnchoring(t *testing.T) {
+	valid := []struct {
+		requirement string
+		expected    RequirementType
+	}{
+		{">=1.2", SingleConditionGreaterThanOrEqual},
+		{"> 1.2", SingleConditionGreaterThan},
+		{"  <= 1.2.3 ", SingleConditionLessThanOrEqual},
+		{"==1.2.3", SingleConditionEqual},
+	}
+	for _, test := range valid {
+		req, err := NewRequirement(test.requirement)
+		if err != nil {
+			t.Errorf("NewRequirement(%q) returned error: %v", test.requirement, err)
+			continue
+		}
+		if req.Type != test.expected {
+			t.Errorf("NewRequirement(%q).Type = %d, want %d", test.requirement, req.Type, test.expected)
+		}
+	}
+
+	invalid := []struct {
+		requirement string
+		expected    error
+	}{
+		{"1.2>3", ErrInvalidRequirement},
+		{"1.2.3>=", ErrInvalidRequirement},
+		{">=1.2 garbage", ErrInvalidRequirement},
+		{">=", ErrInvalidRequirement},
+		{"=>1.2", ErrInvalidOperator},
+		{"<>1.2", ErrInvalidOperator},
+	}
+	for _, test := range invalid {
+		_, err := NewRequirement(test.requirement)
+		if !errors.Is(err, test.expected) {
+			t.Errorf("NewRequirement(%q) error = %v, want %v", test.requirement, err, test.expected)
+		}
+	}
+}
+
+func TestSatisfiesE(t *testing.T) {
+	satisfied, err := SatisfiesE("1.2.3", "~1.2")
+	if err != nil || !satisfied {
+		t.Errorf("SatisfiesE(1.2.3, ~1.2) = %t, %v, want true, nil", satisfied, err)
+	}
+
+	_, err = SatisfiesE("2023c", "~2023")
+	if !errors.Is(err, ErrInvalidVersion) {
+		t.Errorf("SatisfiesE(2023c, ~2023) error = %v, want %v", err, ErrInvalidVersion)
+	}
+
+	_, err = SatisfiesE("1.2.3", "1.2>3")
+	if !errors.Is(err, ErrInvalidRequirement) {
+		t.Errorf("SatisfiesE(1.2.3, 1.2>3) error = %v, want %v", err, ErrInvalidRequirement)
+	}
+}
+
+func TestParseStrictVersion(t *testing.T) {
+	for _, version := range []string{"1.2.3", "v1.2.3", "2023.10.1"} {
+		if _, err := ParseStrictVersion(version); err != nil {
+			t.Errorf("ParseStrictVersion(%s) returned error: %v", version, err)
+		}
+	}
+
+	// CalVer tools such as ones versioned 2023.05 fail under strict mode.
+	for _, version := range []string{"2023.05", "1", "1.2"} {
+		if _, err := ParseStrictVersion(version); !errors.Is(err, ErrNotStrictSemver) {
+			t.Errorf("ParseStrictVersion(%s) error = %v, want %v", version, err, ErrNotStrictSemver)
+		}
+	}
+	for _, version := range []string{"2023c", "abc1234"} {
+		if _, err := ParseStrictVersion(version); !errors.Is(err, ErrInvalidVersion) {
+			t.Errorf("ParseStrictVersion(%s) error = %v, want %v", version, err, ErrInvalidVersion)
+		}
+	}
+}
+
+func TestCheckMinComponents(t *testing.T) {
+	for _, tc := range []struct {
+		version string
+		min     int
+		wantErr error
+	}{
+		{"3", 1, nil},
+		{"3", 2, ErrTooFewComponents},
+		{"3.1", 2, nil},
+		{"3.1", 3, ErrTooFewComponents},
+		{"v3.1.4", 3, nil},
+		{"abc", 1, ErrInvalidVersion},
+	} {
+		err := CheckMinComponents(tc.version, tc.min)
+		if !errors.Is(err, tc.wantErr) {
+			t.Errorf("CheckMinComponents(%s, %d) error = %v, want %v", tc.version, tc.min, err, tc.wantErr)
+		}
+	}
+}
+
+func TestSatisfiesPessimistic(t *testing.T) {
+	tests := []struct {
+		version     string
+		requirement string
+		expected    bool
+	}{
+		{"1.2.0", "~> 1.2", true},
+		{"1.9.0", "~> 1.2", true},
+		{"2.0.0", "~> 1.2", false},
+		{"1.1.9", "~> 1.2", false},
+		{"1.2.3", "~> 1.2.3", true},
+		{"1.2.9", "~> 1.2.3", true},
+		{"1.3.0", "~> 1.2.3", false},
+		{"1.2.2", "~> 1.2.3", false},
+		{"1.5", "~>1", true},
+		{"1.2", "~> 1.2.3", false},
+
+		// "~" differs: ~1.2 does not allow 1.9.0, and allows versions less precise than it.
+		{"1.9.0", "~1.2", false},
+		{"1.2", "~1.2.3", true},
+	}
+	for _, test := range tests {
+		actual, err := SatisfiesE(test.version, test.requirement)
+		if err != nil {
+			t.Errorf("SatisfiesE(%s, %s) returned error: %v", test.version, test.requirement, err)
+			continue
+		}
+		if actual != test.expected {
+			t.Errorf("SatisfiesE(%s, %s) = %t, want %t", test.version, test.requirement, actual, test.expected)
+		}
+	}
+}
+
+func TestSatisfiesRange(t *testing.T) {
+	tests := []struct {
+		version  string
+		expected bool
+	}{
+		{"1.1.9", false},
+		{"1.2", true},
+		{"1.2.0", true},
+		{"1.9.9", true},
+		{"2.0", false},
+		{"2.0.0", false},
+		{"2.0.1", false},
+	}
+	req, err := NewRangeRequirement("1.2", "2.0")
+	if err != nil {
+		t.Fatalf("NewRangeRequirement returned error: %v", err)
+	}
+	for _, test := range tests {
+		actual, err := SatisfiesRequirement(test.version, *req)
+		if err != nil {
+			t.Errorf("SatisfiesRequirement(%s, [1.2, 2.0)) returned error: %v", test.version, err)
+			continue
+		}
+		if actual != test.expected {
+			t.Errorf("SatisfiesRequirement(%s, [1.2, 2.0)) = %t, want %t", test.version, actual, test.expected)
+		}
+	}
+
+	if _, err := NewRangeRequirement("2.0", "1.2"); !errors.Is(err, ErrInvalidRange) {
+		t.Errorf("NewRangeRequirement(2.0, 1.2) error = %v, want %v", err, ErrInvalidRange)
+	}
+}
+
 func TestRegressionFuzzDoesSemverMatch_01(t *testing.T) {
 	actual := Satisfies("1", "~1.0")
 	if actual != true {
@@ -75,6 +269,61 @@ func TestRegressionFuzzDoesSemverMatch_02(t *testing.T) {
 	}
 }
 
+func TestRegressionFuzzDoesSemverMatch_03(t *testing.T) {
+	actual := Satisfies("2", "~1.2.3")
+	if actual != false {
+		t.Errorf("Satisfies(2, ~1.2.3) = %t, want %t", actual, false)
+	}
+}
+
+// TestSatisfiesMissingComponents checks that no combination of missing components in the version
+// and requirement panics, including requirements built by hand that ParseVersion would not return.
+func TestSatisfiesMissingComponents(t *testing.T) {
+	one, two := 1, 2
+	versions := []SemverVersion{
+		{Major: 1},
+		{Major: 1, Minor: &two},
+		{Major: 1, Minor: &two, Patch: &one},
+		{Major: 1, Patch: &one},
+	}
+	for requirementType := Exact; requirementType <= Pessimistic; requirementType++ {
+		for _, v := range versions {
+			for _, r := range versions {
+				satisfies(v, Requirement{Type: requirementType, Version: r})
+			}
+		}
+	}
+}
+
+func TestSatisfiesTildeLessPreciseVersion(t *testing.T) {
+	tests := []struct {
+		version     string
+		requirement string
+		expected    bool
+	}{
+		{"1.2", "~1.2.3", true},
+		{"1", "~1.2", true},
+		{"1", "~1.2.3", true},
+		{"1.1", "~1.2.3", false},
+		{"1.3", "~1.2", false},
+		{"2", "~1.2", false},
+		{"1.2.2", "~1.2.3", false},
+	}
+	for _, test := range tests {
+		actual := Satisfies(test.version, test.requirement)
+		if actual != test.expected {
+			t.Errorf("Satisfies(%s, %s) = %t, want %t", test.version, test.requirement, actual, test.expected)
+		}
+	}
+
+	// Requirements built by hand may have a patch without a minor version.
+	patch := 3
+	req := Requirement{Type: Tilde, Version: SemverVersion{Major: 1, Patch: &patch}}
+	if !satisfies(*mustParseVersion("1.2.3"), req) {
+		t.Errorf("satisfies(1.2.3, ~1.<nil>.3) = false, want true")
+	}
+}
+
 func FuzzDoesSemverMatch(f *testing.F) {
 	// seed the corpus. each testcase is space delimited, first element is version, second is requirement.
 	for _, testcase := range []string{
@@ -98,6 +347,11 @@ func FuzzDoesSemverMatch(f *testing.F) {
 		"1.2.3 <1.2",
 		"1.2.3 <=1.2",
 		"1.2.3 ==1.2",
+		"1.2 ~1.2.3",
+		"1 ~1.2",
+		"2 ~1.2.3",
+		"1.9.0 ~>1.2",
+		"1.3.0 ~>1.2.3",
 	} {
 		f.Add([]byte(testcase))
 	}
@@ -115,3 +369,421 @@ func FuzzDoesSemverMatch(f *testing.F) {
 		Satisfies(versionString, requirement)
 	})
 }
+
+func TestRequirementStrictness(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected Strictness
+	}{
+		{">=1.2", ">=1.2.0", StrictnessEqual},
+		{"~1.2", ">=1.0", StrictnessStricter},
+		{"1.2.3", "~1.2", StrictnessStricter},
+		{">=1.3", ">=1.2", StrictnessStricter},
+		{">1.2", ">=1.2", StrictnessStricter},
+		{"<2", "<=2", StrictnessStricter},
+		{">=1.0", "~1.2", StrictnessLooser},
+		{"~1", "~1.2", StrictnessLooser},
+		{"~> 1.2", "~1.2", StrictnessLooser},
+		{"~> 1.2.3", "~1.2.3", StrictnessEqual},
+		{"<1.5", ">=1.2", StrictnessIncomparable},
+		{"~1", "~2", StrictnessIncomparable},
+	}
+
+	for _, test := range tests {
+		a, err := NewRequirement(test.a)
+		if err != nil {
+			t.Fatalf("NewRequirement(%s) returned error: %v", test.a, err)
+		}
+		b, err := NewRequirement(test.b)
+		if err != nil {
+			t.Fatalf("NewRequirement(%s) returned error: %v", test.b, err)
+		}
+		actual := RequirementStrictness(*a, *b)
+		if actual != test.expected {
+			t.Errorf("RequirementStrictness(%s, %s) = %d, want %d", test.a, test.b, actual, test.expected)
+		}
+	}
+}
+
+func TestRequirementStringStrictness(t *testing.T) {
+	tests := []struct {
+		a        string
+		b        string
+		expected Strictness
+	}{
+		{">=1.21.0 <1.22.0", "~1.21", StrictnessEqual},
+		{">= 1.21.0 < 1.21.5", "~1.21", StrictnessStricter},
+		{">=1.21.0 <1.22.0", ">= 1.19", StrictnessStricter},
+		{">=1.19 <2", ">=1.21.0 <1.22.0", StrictnessLooser},
+		{">=1.17 <1.22", ">= 1.19", StrictnessIncomparable},
+		{"~1.21", ">=1.19 <1.22 >1.20", StrictnessStricter},
+		{"=1.20.3 || =1.21.5", ">=1.20 <1.22", StrictnessStricter},
+		{"=1.20.3 || =1.21.5", "=1.21.5 || =1.20.3", StrictnessEqual},
+		{"=1.20.3 || =1.21.5", "=1.20.3", StrictnessLooser},
+		{"~1.20 || ~1.22", "~1.20 || ~1.21", StrictnessIncomparable},
+		{">=1.2 <1.4", "~1.2 || ~1.3", StrictnessLooser},
+	}
+	for _, test := range tests {
+		actual, err := RequirementStringStrictness(test.a, test.b)
+		if err != nil || actual != test.expected {
+			t.Errorf("RequirementStringStrictness(%s, %s) = %d, %v, want %d", test.a, test.b, actual, err, test.expected)
+		}
+	}
+	if _, err := RequirementStringStrictness(">=1.21.0 <bad", "~1.21"); !errors.Is(err, ErrInvalidRequirement) {
+		t.Errorf("RequirementStringStrictness with an invalid clause error = %v, want %v", err, ErrInvalidRequirement)
+	}
+}
+
+func TestCompareToRequirement(t *testing.T) {
+	tests := []struct {
+		version     string
+		requirement string
+		expected    int
+	}{
+		{"1.19.0", ">= 1.20", -1},
+		{"1.22.0", "< 1.22", 1},
+		{"1.22.1", "<= 1.22", 1},
+		{"1.21.0", "~1.22", -1},
+		{"1.23.0", "~1.22", 1},
+		{"2.0.0", "~> 1.2", 1},
+		{"1.2.3", "1.2.4", -1},
+		{"1.2.3", "1.2.3", 0},
+	}
+	for _, test := range tests {
+		req, err := NewRequirement(test.requirement)
+		if err != nil {
+			t.Fatalf("NewRequirement(%s) returned error: %v", test.requirement, err)
+		}
+		actual := CompareToRequirement(*mustParseVersion(test.version), *req)
+		if actual != test.expected {
+			t.Errorf("CompareToRequirement(%s, %s) = %d, want %d", test.version, test.requirement, actual, test.expected)
+		}
+	}
+}
+
+func TestSatisfiesAnyOf(t *testing.T) {
+	allowlist := "=1.20.3 || =1.21.5"
+	tests := []struct {
+		version  string
+		expected bool
+	}{
+		{"1.20.3", true},
+		{"1.21.5", true},
+		{"1.21.4", false},
+		{"1.20.4", false},
+	}
+	for _, test := range tests {
+		actual, err := SatisfiesE(test.version, allowlist)
+		if err != nil {
+			t.Errorf("SatisfiesE(%s, %s) returned error: %v", test.version, allowlist, err)
+			continue
+		}
+		if actual != test.expected {
+			t.Errorf("SatisfiesE(%s, %s) = %t, want %t", test.version, allowlist, actual, test.expected)
+		}
+	}
+
+	for _, requirement := range []string{"=1.20.3 ||", "=1.20.3 || ^bad"} {
+		if _, err := SatisfiesE("1.20.3", requirement); !errors.Is(err, ErrInvalidRequirement) {
+			t.Errorf("SatisfiesE(1.20.3, %s) error = %v, want %v", requirement, err, ErrInvalidRequirement)
+		}
+	}
+}
+
+func TestSatisfiesSpaceSeparated(t *testing.T) {
+	tests := []struct {
+		version     string
+		requirement string
+		expected    bool
+	}{
+		{"1.2.0", ">=1.2.0 <2.0.0", true},
+		{"1.9.9", ">=1.2.0 <2.0.0", true},
+		{"2.0.0", ">=1.2.0 <2.0.0", false},
+		{"1.1.9", ">=1.2.0 <2.0.0", false},
+		{"1.5.0", ">= 1.2.0 < 2.0.0", true},
+		{"2.0.0", ">= 1.2.0 < 2.0.0", false},
+		{"1.4.2", "~1.4 >1.4.1", true},
+		{"1.4.1", "~1.4 >1.4.1", false},
+		{"3.0.0", ">=1.2.0 <2.0.0 || >=3.0.0 <3.1.0", true},
+		{"2.5.0", ">=1.2.0 <2.0.0 || >=3.0.0 <3.1.0", false},
+	}
+	for _, test := range tests {
+		actual, err := SatisfiesE(test.version, test.requirement)
+		if err != nil {
+			t.Errorf("SatisfiesE(%s, %s) returned error: %v", test.version, test.requirement, err)
+			continue
+		}
+		if actual != test.expected {
+			t.Errorf("SatisfiesE(%s, %s) = %t, want %t", test.version, test.requirement, actual, test.expected)
+		}
+	}
+
+	if _, err := SatisfiesE("1.5.0", ">=1.2.0 <bad"); !errors.Is(err, ErrInvalidRequirement) {
+		t.Errorf("SatisfiesE(1.5.0, >=1.2.0 <bad) error = %v, want %v", err, ErrInvalidRequirement)
+	}
+	if err := CheckRequirement(">=2023a <2024a", DateComparator{}); err != nil {
+		t.Errorf("CheckRequirement(>=2023a <2024a, date) returned error: %v", err)
+	}
+	if satisfied, err := SatisfiesWith("2023c", ">=2023a <2024a", DateComparator{}); err != nil || !satisfied {
+		t.Errorf("SatisfiesWith(2023c, >=2023a <2024a, date) = %t, %v, want true", satisfied, err)
+	}
+	if cmp, err := CompareToRequirementWith("2.1.0", ">=1.2.0 <2.0.0", SemverComparator{}); err != nil || cmp != 1 {
+		t.Errorf("CompareToRequirementWith(2.1.0, >=1.2.0 <2.0.0) = %d, %v, want 1", cmp, err)
+	}
+}
+
+func TestCompareToRequirementWithAlternatives(t *testing.T) {
+	tests := []struct {
+		version     string
+		requirement string
+		expected    int
+	}{
+		{"1.19.0", "=1.20.3 || =1.21.5", -1},
+		{"1.21.0", "=1.20.3 || =1.21.5", 0},
+		{"1.22.0", "=1.20.3 || =1.21.5", 1},
+		{"1.21.5", "=1.20.3 || =1.21.5", 0},
+		{"2.1.0", "~1.20 || >=1.22.0 <2.0.0", 1},
+		{"1.21.0", "~1.20 || >=1.22.0 <2.0.0", 0},
+	}
+	for _, test := range tests {
+		cmp, err := CompareToRequirementWith(test.version, test.requirement, SemverComparator{})
+		if err != nil || cmp != test.expected {
+			t.Errorf("CompareToRequirementWith(%s, %s) = %d, %v, want %d", test.version, test.requirement, cmp, err, test.expected)
+		}
+	}
+	if _, err := CompareToRequirementWith("1.21.0", "=1.20.3 ||", SemverComparator{}); !errors.Is(err, ErrInvalidRequirement) {
+		t.Errorf("CompareToRequirementWith with an empty alternative error = %v, want %v", err, ErrInvalidRequirement)
+	}
+}
+
+func TestDescribeGap(t *testing.T) {
+	tests := []struct {
+		version     string
+		requirement string
+		expected    string
+	}{
+		{"1.19.5", "^1.21", "2 minor versions behind"},
+		{"1.21.3", "^1.21", "3 patch versions ahead"},
+		{"1.24.1", "~1.22", "2 minor versions ahead"},
+		{"1.23.0", "~1.22", "1 minor version ahead"},
+		{"1.21.9", "~1.22", "1 minor version behind"},
+		{"1.9.0", ">= 2.0", "1 major version behind"},
+		{"1.2.3", "> 1.2.3", "1 patch version behind"},
+		{"1.22.5", "< 1.22", "1 minor version ahead"},
+		{"3.1.0", "< 2.0", "2 major versions ahead"},
+		{"1.22.1", "<= 1.22.0", "1 patch version ahead"},
+		{"1.22.0", "~1.22", ""},
+		{"1.2.3-rc1", "=1.2.3", "pre-release of 1.2.3"},
+		{"1.2.3-rc1", ">= 1.2.3", "pre-release of 1.2.3"},
+		{"1.2.3-rc1", "> 1.2.3", "1 patch version behind"},
+	}
+	for _, test := range tests {
+		req, err := NewRequirement(test.requirement)
+		if err != nil {
+			t.Fatalf("NewRequirement(%s) returned error: %v", test.requirement, err)
+		}
+		actual := DescribeGap(*mustParseVersion(test.version), *req)
+		if actual != test.expected {
+			t.Errorf("DescribeGap(%s, %s) = %q, want %q", test.version, test.requirement, actual, test.expected)
+		}
+	}
+}
+
+func TestExplainRequirement(t *testing.T) {
+	tests := []struct {
+		version     string
+		requirement string
+		want        string
+	}{
+		{
+			"1.21.0", "^1.21.0",
+			"requirement ^1.21.0 parsed as caret; lower bound 1.21.0 (inclusive); upper bound 1.21.0 (inclusive); found 1.21.0, which is in range → satisfied",
+		},
+		{
+			"1.22.1", "^1.21.0",
+			"requirement ^1.21.0 parsed as caret; lower bound 1.21.0 (inclusive); upper bound 1.21.0 (inclusive); found 1.22.1, which is above the upper bound → not satisfied",
+		},
+		{
+			"1.21.3", "~1.21",
+			"requirement ~1.21 parsed as tilde; lower bound 1.21.0 (inclusive); upper bound 1.22.0 (exclusive); found 1.21.3, which is in range → satisfied",
+		},
+		{
+			"1.20.9", "~1.21",
+			"requirement ~1.21 parsed as tilde; lower bound 1.21.0 (inclusive); upper bound 1.22.0 (exclusive); found 1.20.9, which is below the lower bound → not satisfied",
+		},
+		{
+			"1.5.0", ">= 1.2",
+			"requirement >=1.2 parsed as greater than or equal; lower bound 1.2.0 (inclusive); no upper bound; found 1.5.0, which is in range → satisfied",
+		},
+	}
+	for _, tt := range tests {
+		req, err := NewRequirement(tt.requirement)
+		if err != nil {
+			t.Fatalf("NewRequirement(%q) returned error: %v", tt.requirement, err)
+		}
+		if got := ExplainRequirement(tt.version, *req); got != tt.want {
+			t.Errorf("ExplainRequirement(%q, %q) = %q, want %q", tt.version, tt.requirement, got, tt.want)
+		}
+	}
+}
+
+func TestExplainWith(t *testing.T) {
+	got, err := ExplainWith("1.21.5", "=1.20.3 || ~1.21", SemverComparator{})
+	if err != nil {
+		t.Fatalf("ExplainWith returned error: %v", err)
+	}
+	lines := strings.Split(got, "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "requirement =1.20.3 parsed as equal;") ||
+		!strings.HasSuffix(lines[1], "found 1.21.5, which is in range → satisfied") {
+		t.Errorf("ExplainWith() = %q, want one satisfied line per alternative", got)
+	}
+
+	got, err = ExplainWith("2023c", ">= 2023a", DateComparator{})
+	if err != nil {
+		t.Fatalf("ExplainWith returned error: %v", err)
+	}
+	if want := "requirement >= 2023a compared as date versions; found 2023c → satisfied"; got != want {
+		t.Errorf("ExplainWith() = %q, want %q", got, want)
+	}
+}
+
+func TestComparePrereleases(t *testing.T) {
+	// The precedence example from https://semver.org/#spec-item-11, in increasing order.
+	versions := []string{
+		"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta",
+		"1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0",
+	}
+	for i := 0; i+1 < len(versions); i++ {
+		a, b := mustParseVersion(versions[i]), mustParseVersion(versions[i+1])
+		if c := CompareSemverVersions(*a, *b); c != -1 {
+			t.Errorf("CompareSemverVersions(%s, %s) = %d, want -1", versions[i], versions[i+1], c)
+		}
+		if c := CompareSemverVersions(*b, *a); c != 1 {
+			t.Errorf("CompareSemverVersions(%s, %s) = %d, want 1", versions[i+1], versions[i], c)
+		}
+	}
+
+	if v := mustParseVersion("1.0.0-rc.1+build.5"); v.Prerelease != "rc.1" || v.String() != "1.0.0-rc.1" {
+		t.Errorf("ParseVersion(1.0.0-rc.1+build.5) = %s, want 1.0.0-rc.1", v)
+	}
+	for _, version := range []string{"1.0.0-", "1.0.0-rc..1", "1.0.0-rc_1"} {
+		if _, err := ParseVersion(version); err == nil {
+			t.Errorf("ParseVersion(%s) returned no error", version)
+		}
+	}
+}
+
+func TestSatisfiesPrerelease(t *testing.T) {
+	// Mirrors the pre-release examples in https://github.com/npm/node-semver#prerelease-tags, with
+	// caret meaning an exact version as it does elsewhere.
+	tests := []struct {
+		version     string
+		requirement string
+		expected    bool
+	}{
+		{"1.2.3-alpha.7", ">1.2.3-alpha.3", true},
+		{"3.4.5-alpha.9", ">1.2.3-alpha.3", false},
+		{"3.4.5", ">1.2.3-alpha.3", true},
+		{"1.2.3-alpha.3", ">=1.2.3-alpha.3 <1.2.4", true},
+		{"1.2.3-alpha.2", ">=1.2.3-alpha.3 <1.2.4", false},
+		{"1.2.3-rc1", "^1.0.0", false},
+		{"1.2.3-rc1", ">=1.0.0", false},
+		{"1.2.3-rc1", "<1.2.3", false},
+		{"1.2.3-rc1", "^1.2.3-rc0", true},
+		{"1.2.3", "^1.2.3-rc0", true},
+		{"1.2.3-alpha", "^1.2.3-rc0", false},
+		{"1.2.4-rc0", "^1.2.3-rc0", false},
+		{"1.2.3-beta.4", "~1.2.3-beta.2", true},
+		{"1.2.4-beta.2", "~1.2.3-beta.2", false},
+		{"1.2.4", "~1.2.3-beta.2", true},
+		{"1.2.3-beta.1", "~1.2.3-beta.2", false},
+		{"1.2.3-rc1", "=1.2.3-rc1", true},
+		{"1.2.3-rc2", "=1.2.3-rc1", false},
+		{"1.2.3-rc1", ">=1.0.0 || >=1.2.3-rc0", true},
+	}
+	for _, test := range tests {
+		actual, err := SatisfiesE(test.version, test.requirement)
+		if err != nil {
+			t.Errorf("SatisfiesE(%s, %s) returned error: %v", test.version, test.requirement, err)
+			continue
+		}
+		if actual != test.expected {
+			t.Errorf("SatisfiesE(%s, %s) = %t, want %t", test.version, test.requirement, actual, test.expected)
+		}
+	}
+}
+
+func BenchmarkParseVersion(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		if _, err := ParseVersion("v1.21.3"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNewRequirement(b *testing.B) {
+	requirements := []string{"1.2.3", "^1.2.3", "~1.2", "~> 1.2.3", ">= 1.2.3"}
+	for i := 0; i < b.N; i++ {
+		if _, err := NewRequirement(requirements[i%len(requirements)]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSatisfies(b *testing.B) {
+	requirements := []string{"~1.21", ">=1.20.0 <2.0.0", "=1.20.3 || =1.21.3", "^1.21.3-rc.1"}
+	for i := 0; i < b.N; i++ {
+		Satisfies("1.21.3", requirements[i%len(requirements)])
+	}
+}
+
+func TestRequirementStringParsesBack(t *testing.T) {
+	var reqs []*Requirement
+	for _, requirement := range []string{"^1.21", "~1.2.3", "~> 1.2", "=1.2.3", ">1.2", "<=2.0.1"} {
+		req, err := NewRequirement(requirement)
+		if err != nil {
+			t.Fatalf("NewRequirement(%s) returned error: %v", requirement, err)
+		}
+		reqs = append(reqs, req)
+	}
+	rangeReq, err := NewRangeRequirement("1.2", "2.0")
+	if err != nil {
+		t.Fatalf("NewRangeRequirement(1.2, 2.0) returned error: %v", err)
+	}
+	reqs = append(reqs, rangeReq)
+
+	versions := []string{"1.1.9", "1.2.0", "1.2.3", "1.2.9", "1.21.0", "1.21.5", "1.9.0", "2.0.0", "2.0.1", "2.1.0"}
+	for _, req := range reqs {
+		formatted := req.String()
+		for _, version := range versions {
+			want, err := SatisfiesRequirement(version, *req)
+			if err != nil {
+				t.Fatalf("SatisfiesRequirement(%s, %s) returned error: %v", version, formatted, err)
+			}
+			if got, err := SatisfiesE(version, formatted); err != nil || got != want {
+				t.Errorf("SatisfiesE(%s, %q) = %t, %v, want %t", version, formatted, got, err, want)
+			}
+		}
+	}
+}
+
+func TestAllOf(t *testing.T) {
+	tests := []struct {
+		requirements []string
+		expected     string
+	}{
+		{[]string{">=1.2", "<2.0"}, ">=1.2 <2.0"},
+		{[]string{"~1.21"}, "~1.21"},
+		{[]string{"=1.20.3 || =1.21.5", "<1.21"}, "=1.20.3 <1.21 || =1.21.5 <1.21"},
+		{nil, ""},
+	}
+	for _, test := range tests {
+		if actual := AllOf(test.requirements...); actual != test.expected {
+			t.Errorf("AllOf(%q) = %q, want %q", test.requirements, actual, test.expected)
+		}
+	}
+	if satisfied, err := SatisfiesE("1.21.5", AllOf("=1.20.3 || =1.21.5", "<1.21")); err != nil || satisfied {
+		t.Errorf("SatisfiesE(1.21.5, AllOf(=1.20.3 || =1.21.5, <1.21)) = %t, %v, want false", satisfied, err)
+	}
+}
@@ -0,0 +1,377 @@
+/*
+ * Copyright 2023 Asim Ihsan
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package identifier
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Strictness describes how the range of versions allowed by one requirement relates to the range
+// allowed by another.
+type Strictness int
+
+const (
+	// StrictnessEqual means both requirements allow the same range.
+	StrictnessEqual Strictness = iota
+	// StrictnessStricter means the first requirement allows a subset of the second's range.
+	StrictnessStricter
+	// StrictnessLooser means the first requirement allows a superset of the second's range.
+	StrictnessLooser
+	// StrictnessIncomparable means the ranges only partially overlap, or are disjoint.
+	StrictnessIncomparable
+)
+
+// versionBound is one end of the range of versions that a requirement allows. A nil *versionBound
+// means the range is unbounded at that end.
+type versionBound struct {
+	Version   SemverVersion
+	Inclusive bool
+}
+
+// RequirementStrictness compares the ranges allowed by requirements a and b. For example
+// RequirementStrictness(~1.2, >=1.0) is StrictnessStricter, because every version matching ~1.2
+// also matches >=1.0.
+//
+// Missing minor and patch components are treated as zero when comparing bounds, so this is an
+// approximation of Satisfies for requirements that are less precise than three components.
+func RequirementStrictness(a, b Requirement) Strictness {
+	return clausesStrictness([]*Requirement{&a}, []*Requirement{&b})
+}
+
+// RequirementStringStrictness is like RequirementStrictness, but for requirements as they are
+// written in a config, whose clauses separated by spaces must all be satisfied, e.g.
+// ">=1.21.0 <1.22.0", which is StrictnessEqual to "~1.21". A requirement with alternatives
+// separated by "||" is within another if each of its alternatives is within one of the other's, so
+// a range that is only covered by several alternatives together, e.g. ">=1.2 <1.4" by
+// "~1.2 || ~1.3", is not found to be within them.
+func RequirementStringStrictness(a, b string) (Strictness, error) {
+	aAlternatives, err := parseAlternatives(a)
+	if err != nil {
+		return StrictnessIncomparable, err
+	}
+	bAlternatives, err := parseAlternatives(b)
+	if err != nil {
+		return StrictnessIncomparable, err
+	}
+	return strictness(alternativesWithin(aAlternatives, bAlternatives), alternativesWithin(bAlternatives, aAlternatives)), nil
+}
+
+// alternativesWithin returns true if every alternative of a allows a subset of the range of one of
+// the alternatives of b.
+func alternativesWithin(a, b [][]*Requirement) bool {
+	for _, aClauses := range a {
+		within := false
+		for _, bClauses := range b {
+			if s := clausesStrictness(aClauses, bClauses); s == StrictnessEqual || s == StrictnessStricter {
+				within = true
+				break
+			}
+		}
+		if !within {
+			return false
+		}
+	}
+	return true
+}
+
+// clausesStrictness compares the ranges allowed by every one of the clauses in a with the range
+// allowed by every one of the clauses in b.
+func clausesStrictness(a, b []*Requirement) Strictness {
+	aLower, aUpper := clausesBounds(a)
+	bLower, bUpper := clausesBounds(b)
+
+	aWithinB := compareLowerBounds(aLower, bLower) >= 0 && compareUpperBounds(aUpper, bUpper) <= 0
+	bWithinA := compareLowerBounds(bLower, aLower) >= 0 && compareUpperBounds(bUpper, aUpper) <= 0
+	return strictness(aWithinB, bWithinA)
+}
+
+// strictness returns the Strictness of a requirement a compared with b, given whether each allows a
+// subset of the other's range.
+func strictness(aWithinB, bWithinA bool) Strictness {
+	switch {
+	case aWithinB && bWithinA:
+		return StrictnessEqual
+	case aWithinB:
+		return StrictnessStricter
+	case bWithinA:
+		return StrictnessLooser
+	default:
+		return StrictnessIncomparable
+	}
+}
+
+// CompareToRequirement returns -1 if v is older than every version that req allows, 1 if v is newer
+// than every version that req allows, and 0 otherwise, e.g. if v is within req's range but is
+// excluded by it.
+func CompareToRequirement(v SemverVersion, req Requirement) int {
+	lower, upper := req.bounds()
+	version := zeroFilled(v)
+	if lower != nil {
+		c := CompareSemverVersions(version, lower.Version)
+		if c < 0 || (c == 0 && !lower.Inclusive) {
+			return -1
+		}
+	}
+	if upper != nil {
+		c := CompareSemverVersions(version, upper.Version)
+		if c > 0 || (c == 0 && !upper.Inclusive) {
+			return 1
+		}
+	}
+	return 0
+}
+
+// DescribeGap describes how far v is from the nearest version that req allows, e.g. "2 minor versions
+// behind" or "1 major version ahead". It returns an empty string if v is within req's range.
+func DescribeGap(v SemverVersion, req Requirement) string {
+	lower, upper := req.bounds()
+	version := zeroFilled(v)
+
+	switch CompareToRequirement(v, req) {
+	case -1:
+		component, n := gap(lower.Version, version)
+		if n == 0 && lower.Inclusive && version.Prerelease != "" && lower.Version.Prerelease == "" {
+			// v is a pre-release of the lower bound, e.g. 1.2.3-rc1 for "=1.2.3", which is not
+			// behind by any whole version.
+			release := version
+			release.Prerelease = ""
+			return fmt.Sprintf("pre-release of %s", release)
+		}
+		if n == 0 {
+			// v is an exclusive lower bound, so the nearest allowed version is the next patch.
+			component, n = 2, 1
+		}
+		return formatGap(component, n, "behind")
+	case 1:
+		component, n := gap(version, upper.Version)
+		if !upper.Inclusive {
+			// The nearest allowed version is a step below the bound, e.g. 1.22.x for "< 1.23.0",
+			// so the gap is one step more if v differs from the bound in that step's component,
+			// or one step if v differs in a less significant component.
+			step := leastSignificantComponent(upper.Version)
+			switch {
+			case n == 0 || component > step:
+				component, n = step, 1
+			case component == step:
+				n++
+			}
+		}
+		return formatGap(component, n, "ahead")
+	default:
+		return ""
+	}
+}
+
+// gap returns the most significant component that newer and older differ in, 0 for major, 1 for
+// minor, and 2 for patch, and how far apart they are in it. Both must be zero filled.
+func gap(newer, older SemverVersion) (component int, n int) {
+	newerComponents := []int{newer.Major, *newer.Minor, *newer.Patch}
+	olderComponents := []int{older.Major, *older.Minor, *older.Patch}
+	for i := range newerComponents {
+		if newerComponents[i] != olderComponents[i] {
+			return i, newerComponents[i] - olderComponents[i]
+		}
+	}
+	return 2, 0
+}
+
+// leastSignificantComponent returns the least significant component of a zero filled version that
+// is not zero, e.g. 1 for minor in "1.23.0".
+func leastSignificantComponent(v SemverVersion) int {
+	switch {
+	case *v.Patch != 0:
+		return 2
+	case *v.Minor != 0:
+		return 1
+	case v.Major != 0:
+		return 0
+	default:
+		return 2
+	}
+}
+
+func formatGap(component int, n int, direction string) string {
+	names := []string{"major", "minor", "patch"}
+	noun := "versions"
+	if n == 1 {
+		noun = "version"
+	}
+	return fmt.Sprintf("%d %s %s %s", n, names[component], noun, direction)
+}
+
+// clausesBounds returns the bounds of the range of versions that every one of reqs allows, which is
+// the intersection of their ranges, e.g. 1.21.0 up to 1.22.0 for ">=1.21.0" and "<1.22.0".
+func clausesBounds(reqs []*Requirement) (lower, upper *versionBound) {
+	for _, req := range reqs {
+		reqLower, reqUpper := req.bounds()
+		if compareLowerBounds(reqLower, lower) > 0 {
+			lower = reqLower
+		}
+		if compareUpperBounds(reqUpper, upper) < 0 {
+			upper = reqUpper
+		}
+	}
+	return lower, upper
+}
+
+// bounds returns the lower and upper bounds of the range of versions the requirement allows.
+func (r Requirement) bounds() (lower, upper *versionBound) {
+	version := zeroFilled(r.Version)
+
+	switch r.Type {
+	case Exact, Caret, SingleConditionEqual:
+		return &versionBound{version, true}, &versionBound{version, true}
+	case Tilde:
+		var next SemverVersion
+		if r.Version.Minor == nil {
+			next = zeroFilled(SemverVersion{Major: r.Version.Major + 1})
+		} else {
+			minor := *r.Version.Minor + 1
+			next = zeroFilled(SemverVersion{Major: r.Version.Major, Minor: &minor})
+		}
+		return &versionBound{version, true}, &versionBound{next, false}
+	case Pessimistic:
+		var next SemverVersion
+		if r.Version.Minor == nil || r.Version.Patch == nil {
+			next = zeroFilled(SemverVersion{Major: r.Version.Major + 1})
+		} else {
+			minor := *r.Version.Minor + 1
+			next = zeroFilled(SemverVersion{Major: r.Version.Major, Minor: &minor})
+		}
+		return &versionBound{version, true}, &versionBound{next, false}
+	case SingleConditionGreaterThan:
+		return &versionBound{version, false}, nil
+	case SingleConditionGreaterThanOrEqual:
+		return &versionBound{version, true}, nil
+	case SingleConditionLessThan:
+		return nil, &versionBound{version, false}
+	case SingleConditionLessThanOrEqual:
+		return nil, &versionBound{version, true}
+	case Range:
+		return &versionBound{version, true}, &versionBound{zeroFilled(*r.MaxVersion), false}
+	}
+
+	return nil, nil
+}
+
+// compareLowerBounds returns -1 if x allows more versions than y, 1 if x allows fewer versions
+// than y, and 0 if they are the same.
+func compareLowerBounds(x, y *versionBound) int {
+	switch {
+	case x == nil && y == nil:
+		return 0
+	case x == nil:
+		return -1
+	case y == nil:
+		return 1
+	}
+	if c := CompareSemverVersions(x.Version, y.Version); c != 0 {
+		return c
+	}
+	if x.Inclusive == y.Inclusive {
+		return 0
+	}
+	if x.Inclusive {
+		return -1
+	}
+	return 1
+}
+
+// compareUpperBounds returns 1 if x allows more versions than y, -1 if x allows fewer versions
+// than y, and 0 if they are the same.
+func compareUpperBounds(x, y *versionBound) int {
+	switch {
+	case x == nil && y == nil:
+		return 0
+	case x == nil:
+		return 1
+	case y == nil:
+		return -1
+	}
+	if c := CompareSemverVersions(x.Version, y.Version); c != 0 {
+		return c
+	}
+	if x.Inclusive == y.Inclusive {
+		return 0
+	}
+	if x.Inclusive {
+		return 1
+	}
+	return -1
+}
+
+// ExplainRequirement describes how req was parsed, the range of versions it allows, and whether
+// version satisfies it, e.g. "requirement ~1.21 parsed as tilde; lower bound 1.21.0 (inclusive);
+// upper bound 1.22.0 (exclusive); found 1.21.3, which is in range → satisfied".
+func ExplainRequirement(version string, req Requirement) string {
+	lower, upper := req.bounds()
+	parts := []string{
+		fmt.Sprintf("requirement %s parsed as %s", req, req.Type),
+		describeBound("lower", lower),
+		describeBound("upper", upper),
+	}
+
+	v, err := ParseVersion(version)
+	if err != nil {
+		parts = append(parts, fmt.Sprintf("found %s, which is not a valid version → not satisfied", version))
+		return strings.Join(parts, "; ")
+	}
+	var position string
+	switch CompareToRequirement(*v, req) {
+	case -1:
+		position = "below the lower bound"
+	case 1:
+		position = "above the upper bound"
+	default:
+		position = "in range"
+	}
+	outcome := "satisfied"
+	if !prereleaseAllowed(*v, &req) || !satisfies(*v, req) {
+		outcome = "not satisfied"
+		if position == "in range" {
+			position = "in range but not allowed by the requirement"
+		}
+	}
+	parts = append(parts, fmt.Sprintf("found %s, which is %s → %s", version, position, outcome))
+	return strings.Join(parts, "; ")
+}
+
+// describeBound describes one end of a requirement's range, e.g. "upper bound 2.0.0 (exclusive)".
+func describeBound(end string, bound *versionBound) string {
+	if bound == nil {
+		return "no " + end + " bound"
+	}
+	inclusive := "exclusive"
+	if bound.Inclusive {
+		inclusive = "inclusive"
+	}
+	return fmt.Sprintf("%s bound %s (%s)", end, bound.Version, inclusive)
+}
+
+// zeroFilled returns a copy of v with any missing minor or patch components set to zero.
+func zeroFilled(v SemverVersion) SemverVersion {
+	minor, patch := 0, 0
+	if v.Minor != nil {
+		minor = *v.Minor
+	}
+	if v.Patch != nil {
+		patch = *v.Patch
+	}
+	return SemverVersion{Major: v.Major, Minor: &minor, Patch: &patch, Prerelease: v.Prerelease}
+}